@@ -0,0 +1,143 @@
+package fakedata
+
+import (
+	"fmt"
+	"github.com/debugger84/sqlc-fixture/internal/gotype"
+	"github.com/iancoleman/strcase"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/plugin-sdk-go/sdk"
+	"slices"
+	"strings"
+)
+
+// namePatterns maps the patterns of column names to the generators of string values.
+// The first pattern found among the words of the column name wins,
+// so `user_name` matches `name` but `hostname` does not.
+var namePatterns = []struct {
+	pattern   string
+	generator string
+}{
+	{"email", "fakeEmail"},
+	{"first_name", "fakeFirstName"},
+	{"last_name", "fakeLastName"},
+	{"username", "fakeUsername"},
+	{"login", "fakeUsername"},
+	{"nickname", "fakeUsername"},
+	{"phone", "fakePhone"},
+	{"url", "fakeURL"},
+	{"website", "fakeURL"},
+	{"title", "fakeText"},
+	{"description", "fakeText"},
+	{"body", "fakeText"},
+	{"name", "fakeFullName"},
+}
+
+// intMaxValues are the max values generated for the integer Go types.
+var intMaxValues = map[string]string{
+	"int":    "2147483647",
+	"int8":   "127",
+	"int16":  "32767",
+	"int32":  "2147483647",
+	"int64":  "2147483647",
+	"uint":   "2147483647",
+	"uint8":  "255",
+	"uint16": "65535",
+	"uint32": "2147483647",
+	"uint64": "2147483647",
+}
+
+// pgxV5Types are the pgx/v5 types used for NOT NULL columns, which can be filled with generated values.
+var pgxV5Types = map[string]string{
+	"pgtype.Timestamptz": "pgtype.Timestamptz{Time: fakeTime(), Valid: true}",
+	"pgtype.Timestamp":   "pgtype.Timestamp{Time: fakeTime(), Valid: true}",
+	"pgtype.Date":        "pgtype.Date{Time: fakeTime(), Valid: true}",
+	"pgtype.UUID":        "pgtype.UUID{Bytes: fakeUUID(), Valid: true}",
+}
+
+// Expression returns the Go expression generating a random value for the column
+// or an empty string if there is no generator for the column.
+// Only NOT NULL columns are filled, because NULL is a valid value for the rest of them.
+func Expression(column *plugin.Column, goType *gotype.GoType) string {
+	if !column.GetNotNull() || goType.IsPointer() || goType.IsArray() {
+		return ""
+	}
+	dbType := strings.ToLower(sdk.DataType(column.GetType()))
+	dbType = strings.TrimPrefix(dbType, "pg_catalog.")
+
+	typeName := goType.TypeWithPackage()
+	switch typeName {
+	case "string":
+		if isTextType(dbType) {
+			return stringExpression(column)
+		}
+	case "float32", "float64":
+		return fmt.Sprintf("%s(fakeFloat())", typeName)
+	case "time.Time":
+		if isTimestampType(dbType) {
+			return "fakeTime()"
+		}
+	case "uuid.UUID":
+		return "uuid.UUID(fakeUUID())"
+	}
+	if maxValue, ok := intMaxValues[typeName]; ok && isIntegerType(dbType) && !isReference(column) {
+		return fmt.Sprintf("%s(fakeInt(%s))", typeName, maxValue)
+	}
+	if expr, ok := pgxV5Types[typeName]; ok && strings.Contains(goType.Import().Path, "pgx/v5") {
+		return expr
+	}
+
+	return ""
+}
+
+func stringExpression(column *plugin.Column) string {
+	words := nameWords(column.GetName())
+	for _, p := range namePatterns {
+		if containsWords(words, strings.Split(p.pattern, "_")) {
+			return fmt.Sprintf("%s(%d)", p.generator, column.GetLength())
+		}
+	}
+	return fmt.Sprintf("fakeString(%q, %d)", column.GetName(), column.GetLength())
+}
+
+// nameWords splits the snake or camel case column name into the lower case words.
+func nameWords(name string) []string {
+	return strings.Split(strcase.ToSnake(name), "_")
+}
+
+// containsWords reports whether the words of the pattern follow one another among the words of the name.
+func containsWords(words []string, pattern []string) bool {
+	for i := 0; i+len(pattern) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(pattern)], pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReference reports whether the integer column looks like a foreign key, e.g. `author_id`.
+// A random value would not reference an existing row, so such columns are filled by the relations only.
+func isReference(column *plugin.Column) bool {
+	words := nameWords(column.GetName())
+	return len(words) > 1 && words[len(words)-1] == "id"
+}
+
+func isTextType(dbType string) bool {
+	switch dbType {
+	case "text", "citext", "string", "name", "clob":
+		return true
+	}
+	return strings.Contains(dbType, "char") || strings.HasSuffix(dbType, "text")
+}
+
+// isIntegerType reports whether the column stores integers set by a client.
+// Serial columns are skipped because their values are generated by the database.
+func isIntegerType(dbType string) bool {
+	if dbType == "interval" {
+		return false
+	}
+	return strings.Contains(dbType, "int") || dbType == "year"
+}
+
+func isTimestampType(dbType string) bool {
+	return strings.HasPrefix(dbType, "timestamp") || strings.HasPrefix(dbType, "date")
+}
@@ -0,0 +1,54 @@
+package fakedata_test
+
+import (
+	"github.com/debugger84/sqlc-fixture/internal/fakedata"
+	"github.com/debugger84/sqlc-fixture/internal/gotype"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpression(t *testing.T) {
+	column := func(name string, dbType string, notNull bool, length int32) *plugin.Column {
+		return &plugin.Column{Name: name, NotNull: notNull, Length: length, Type: &plugin.Identifier{Name: dbType}}
+	}
+	tests := []struct {
+		name   string
+		column *plugin.Column
+		goType string
+		want   string
+	}{
+		{"email", column("email", "varchar", true, 64), "string", "fakeEmail(64)"},
+		{"user name", column("user_name", "text", true, 0), "string", "fakeFullName(0)"},
+		{"first name", column("first_name", "text", true, 0), "string", "fakeFirstName(0)"},
+		{"other text", column("code", "pg_catalog.varchar", true, 10), "string", `fakeString("code", 10)`},
+		{"name inside a word", column("hostname", "text", true, 0), "string", `fakeString("hostname", 0)`},
+		{"camel case name", column("lastName", "text", true, 0), "string", "fakeLastName(0)"},
+		{"name before another word", column("name_en", "text", true, 0), "string", "fakeFullName(0)"},
+		{"nullable", column("email", "text", false, 0), "sql.NullString", ""},
+		{"numeric string", column("price", "decimal", true, 0), "string", ""},
+		{"integer", column("amount", "pg_catalog.int4", true, 0), "int32", "int32(fakeInt(2147483647))"},
+		{"serial", column("id", "bigserial", true, 0), "int64", ""},
+		{"integer id", column("id", "bigint", true, 0), "int64", "int64(fakeInt(2147483647))"},
+		{"foreign key", column("author_id", "bigint", true, 0), "int64", ""},
+		{"camel case foreign key", column("authorId", "int", true, 0), "int32", ""},
+		{"interval", column("duration", "interval", true, 0), "int64", ""},
+		{"timestamp", column("created_at", "pg_catalog.timestamptz", true, 0), "time.Time", "fakeTime()"},
+		{"time", column("starts_at", "time", true, 0), "time.Time", ""},
+		{"uuid", column("id", "uuid", true, 0), "github.com/gofrs/uuid.UUID", "uuid.UUID(fakeUUID())"},
+		{
+			"pgx/v5 timestamp",
+			column("created_at", "timestamptz", true, 0),
+			"github.com/jackc/pgx/v5/pgtype.Timestamptz",
+			"pgtype.Timestamptz{Time: fakeTime(), Valid: true}",
+		},
+		{"array", column("tags", "text", true, 0), "[]string", ""},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, fakedata.Expression(tt.column, gotype.NewGoType(tt.goType)))
+			},
+		)
+	}
+}
@@ -16,15 +16,22 @@ func Generate(ctx context.Context, req *plugin.GenerateRequest) (*plugin.Generat
 		return nil, err
 	}
 
-	if err := opts.ValidateOpts(options); err != nil {
+	if err := opts.ValidateOpts(options, req.Catalog); err != nil {
 		return nil, err
 	}
 
+	// Like the golang plugin, the tables of the default schema get the names without the schema prefix,
+	// so without the default_schema option the default schema of the catalog is used.
 	if options.DefaultSchema != "" {
 		req.Catalog.DefaultSchema = options.DefaultSchema
+	} else {
+		options.DefaultSchema = req.Catalog.DefaultSchema
 	}
 	customTypes := sqltype.NewCustomTypes(req.Catalog.Schemas, options)
-	structs := model.BuildStructs(req, options, customTypes)
+	structs, err := model.BuildStructs(req, options, customTypes)
+	if err != nil {
+		return nil, err
+	}
 
 	importer := imports.NewImportBuilder(options)
 
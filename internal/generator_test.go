@@ -0,0 +1,429 @@
+package internal_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/debugger84/sqlc-fixture/internal"
+	"github.com/debugger84/sqlc-fixture/internal/model"
+	"github.com/debugger84/sqlc-fixture/internal/naming"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"github.com/debugger84/sqlc-fixture/internal/sqltype"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/stretchr/testify/require"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const modelImport = "example.com/app/models"
+
+func column(table *plugin.Identifier, name, sqlType string, notNull bool) *plugin.Column {
+	columnType := &plugin.Identifier{Name: sqlType}
+	if schema, typeName, found := strings.Cut(sqlType, "."); found {
+		columnType = &plugin.Identifier{Schema: schema, Name: typeName}
+	}
+	return &plugin.Column{Name: name, NotNull: notNull, Table: table, Type: columnType}
+}
+
+// table creates the table with the columns written as "name type" with the "not null" suffix for NOT NULL columns.
+func table(schema, name string, columns ...string) *plugin.Table {
+	rel := &plugin.Identifier{Schema: schema, Name: name}
+	t := &plugin.Table{Rel: rel}
+	for _, c := range columns {
+		definition, notNull := strings.CutSuffix(c, " not null")
+		columnName, sqlType, _ := strings.Cut(definition, " ")
+		t.Columns = append(t.Columns, column(rel, columnName, sqlType, notNull))
+	}
+	return t
+}
+
+// generate runs the plugin and returns the contents of the generated files by their names.
+func generate(t *testing.T, engine opts.SQLEngine, catalog *plugin.Catalog, pluginOptions string) map[string]string {
+	t.Helper()
+	resp, err := internal.Generate(context.Background(), newRequest(engine, catalog, pluginOptions))
+	require.NoError(t, err)
+	files := make(map[string]string, len(resp.Files))
+	for _, file := range resp.Files {
+		files[file.Name] = string(file.Contents)
+	}
+	return files
+}
+
+func newRequest(engine opts.SQLEngine, catalog *plugin.Catalog, pluginOptions string) *plugin.GenerateRequest {
+	return &plugin.GenerateRequest{
+		Settings:      &plugin.Settings{Engine: string(engine)},
+		Catalog:       catalog,
+		PluginOptions: []byte(pluginOptions),
+	}
+}
+
+// models returns the source of the models generated by sqlc for the database/sql package.
+func models(t *testing.T, engine opts.SQLEngine, catalog *plugin.Catalog, pluginOptions string) string {
+	t.Helper()
+	req := newRequest(engine, catalog, pluginOptions)
+	options, err := opts.Parse(req)
+	require.NoError(t, err)
+	options.ModelImport = ""
+	if options.DefaultSchema == "" {
+		options.DefaultSchema = catalog.DefaultSchema
+	}
+	structs, err := model.BuildStructs(req, options, sqltype.NewCustomTypes(catalog.Schemas, options))
+	require.NoError(t, err)
+
+	imports := map[string]bool{"context": true, "database/sql": true}
+	var body strings.Builder
+	body.WriteString(
+		`type DBTX interface {
+			ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+			PrepareContext(context.Context, string) (*sql.Stmt, error)
+			QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+			QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+		}
+		`,
+	)
+	normalizer := naming.NewNameNormalizer(options)
+	for _, schema := range catalog.Schemas {
+		for _, enum := range schema.Enums {
+			name := normalizer.NormalizeGoType(normalizer.NormalizeSqlName(schema.Name, enum.Name))
+			fmt.Fprintf(&body, "type %[1]s string\ntype Null%[1]s struct {\n%[1]s %[1]s\nValid bool\n}\n", name)
+		}
+	}
+	for _, s := range structs {
+		fmt.Fprintf(&body, "type %s struct {\n", s.Type().TypeName())
+		for _, f := range s.Fields() {
+			fmt.Fprintf(&body, "%s %s\n", f.Name(), f.Type().String())
+			if p := f.Type().Import().Path; p != "" {
+				imports[p] = true
+			}
+		}
+		body.WriteString("}\n")
+	}
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, fmt.Sprintf("%q", p))
+	}
+	sort.Strings(paths)
+	return "package models\n\nimport (\n" + strings.Join(paths, "\n") + "\n)\n\n" + body.String()
+}
+
+// modelsImporter imports the models package from the source and the other packages by the source importer.
+type modelsImporter struct {
+	fset   *token.FileSet
+	models *types.Package
+	source types.Importer
+}
+
+func (i *modelsImporter) Import(path string) (*types.Package, error) {
+	if path == modelImport && i.models != nil {
+		return i.models, nil
+	}
+	return i.source.Import(path)
+}
+
+// typeCheck checks the generated files together with the models.
+// The files of the fixture package are placed in its folder, the others belong to the models package.
+func typeCheck(t *testing.T, files map[string]string, modelsSource string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	imp := &modelsImporter{fset: fset, source: importer.ForCompiler(fset, "source", nil)}
+	parse := func(name, source string) *ast.File {
+		file, err := parser.ParseFile(fset, name, source, 0)
+		require.NoError(t, err, name)
+		return file
+	}
+	check := func(pkg string, files []*ast.File) *types.Package {
+		conf := types.Config{Importer: imp}
+		checked, err := conf.Check(pkg, fset, files, nil)
+		require.NoError(t, err)
+		return checked
+	}
+
+	modelFiles := []*ast.File{parse("models.go", modelsSource)}
+	fixtureFiles := make([]*ast.File, 0, len(files))
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if path.Dir(name) == "." {
+			modelFiles = append(modelFiles, parse(name, files[name]))
+		} else {
+			fixtureFiles = append(fixtureFiles, parse(name, files[name]))
+		}
+	}
+	imp.models = check(modelImport, modelFiles)
+	if len(fixtureFiles) > 0 {
+		check("example.com/app/fixture", fixtureFiles)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	pgCatalog := &plugin.Catalog{
+		DefaultSchema: "public",
+		Schemas: []*plugin.Schema{
+			{
+				Name:           "public",
+				Enums:          []*plugin.Enum{{Name: "user_status", Vals: []string{"active", "suspended"}}},
+				CompositeTypes: []*plugin.CompositeType{{Name: "address"}},
+				Tables: []*plugin.Table{
+					table(
+						"public", "users",
+						"id bigserial not null",
+						"email text not null",
+						"status user_status not null",
+						"home address",
+						"created_at pg_catalog.timestamptz not null",
+						"bio text",
+					),
+					table(
+						"public", "posts",
+						"id bigserial not null",
+						"author_id pg_catalog.int8 not null",
+						"title text not null",
+						"state user_status",
+					),
+					table("public", "tags", "post_id pg_catalog.int8 not null", "name text not null"),
+				},
+			},
+			{
+				Name:   "audit",
+				Tables: []*plugin.Table{table("audit", "logs", "id bigserial not null", "message text not null")},
+			},
+		},
+	}
+	mysqlCatalog := &plugin.Catalog{
+		DefaultSchema: "public",
+		Schemas: []*plugin.Schema{
+			{
+				Name: "public",
+				Tables: []*plugin.Table{
+					table("public", "users", "id bigint not null", "email varchar not null", "created_at datetime not null"),
+					table(
+						"public", "memberships",
+						"tenant_id int not null",
+						"user_id bigint not null",
+						"role varchar not null",
+						"joined_at datetime not null",
+					),
+				},
+			},
+		},
+	}
+	sqliteCatalog := &plugin.Catalog{
+		DefaultSchema: "main",
+		Schemas: []*plugin.Schema{
+			{
+				Name: "main",
+				Tables: []*plugin.Table{
+					table("", "users", "id integer not null", "email text not null", "created_at datetime"),
+					table("", "posts", "id integer not null", "author_id integer not null", "title text not null"),
+				},
+			},
+		},
+	}
+
+	supportCatalog := &plugin.Catalog{
+		DefaultSchema: "main",
+		Schemas: []*plugin.Schema{
+			{
+				Name: "main",
+				Tables: []*plugin.Table{
+					table("", "sessions", "id integer not null", "token text not null"),
+					table("", "savepoints", "id integer not null"),
+					table("", "test_contexts", "id integer not null"),
+					table("", "fixtures", "id integer not null"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		engine  opts.SQLEngine
+		catalog *plugin.Catalog
+		options string
+	}{
+		{
+			name:    "postgresql",
+			engine:  opts.SQLEnginePostgresql,
+			catalog: pgCatalog,
+			options: `{
+				"package": "fixture",
+				"model_import": "` + modelImport + `",
+				"sql_package": "database/sql",
+				"primary_keys_columns": ["users.id", "posts.id", "audit.logs.id"],
+				"unique_columns": ["users.email"],
+				"relations": ["posts.author_id -> users.id"],
+				"composite_types": ["address(street text, zip_code int4)"],
+				"cleanup": [
+					{"table": "audit.logs", "strategy": "truncate_cascade"},
+					{"table": "tags", "strategy": "delete_where", "where": "name LIKE 'test%'\n  AND post_id > 0"},
+					{"table": "posts", "strategy": "delete_where", "where": "title LIKE 'test%'\nOR title = ''", "reset_sequences": true},
+					{"table": "users", "reset_sequences": true}
+				],
+				"emit_fake_data": true
+			}`,
+		},
+		{
+			name:    "mysql",
+			engine:  opts.SQLEngineMySQL,
+			catalog: mysqlCatalog,
+			options: `{
+				"package": "fixture",
+				"model_import": "` + modelImport + `",
+				"sql_package": "database/sql",
+				"primary_keys_columns": ["users.id"],
+				"natural_keys_columns": ["(memberships.tenant_id, memberships.user_id)"],
+				"relations": ["memberships.user_id -> users.id"],
+				"emit_fake_data": true
+			}`,
+		},
+		{
+			name:    "sqlite",
+			engine:  opts.SQLEngineSQLite,
+			catalog: sqliteCatalog,
+			options: `{
+				"package": "fixture",
+				"model_import": "` + modelImport + `",
+				"sql_package": "database/sql",
+				"primary_keys_columns": ["users.id", "posts.id"],
+				"relations": ["posts.author_id -> users.id"],
+				"unique_columns": ["users.email"],
+				"exclude": ["UpdateUserEmailParams.ID"]
+			}`,
+		},
+		{
+			name:    "tables named like the support code",
+			engine:  opts.SQLEngineSQLite,
+			catalog: supportCatalog,
+			options: `{
+				"package": "fixture",
+				"model_import": "` + modelImport + `",
+				"sql_package": "database/sql",
+				"primary_keys_columns": ["sessions.id", "savepoints.id", "test_contexts.id", "fixtures.id"]
+			}`,
+		},
+	} {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				files := generate(t, tt.engine, tt.catalog, tt.options)
+				typeCheck(t, files, models(t, tt.engine, tt.catalog, tt.options))
+			},
+		)
+	}
+}
+
+func TestGenerateInvalidOptions(t *testing.T) {
+	catalog := &plugin.Catalog{
+		DefaultSchema: "main",
+		Schemas: []*plugin.Schema{
+			{
+				Name:   "main",
+				Tables: []*plugin.Table{table("", "users", "id integer not null", "email text not null")},
+			},
+		},
+	}
+	for _, tt := range []struct {
+		options string
+		err     string
+	}{
+		{
+			options: `{"package": "fixture", "model_import": "` + modelImport + `", "sql_package": "pgx/v5"}`,
+			err:     "invalid sql_package: SQL package pgx/v5 supports only the postgresql engine, not sqlite",
+		},
+		{
+			options: `{"package": "fixture", "model_import": "` + modelImport + `", "exclude": ["User.nickname"]}`,
+			err:     `invalid exclude: field "User.nickname" is not found`,
+		},
+		{
+			options: `{"package": "fixture", "model_import": "` + modelImport + `", "cleanup": [{"table": "posts"}]}`,
+			err:     `invalid cleanup: table "posts" is not found`,
+		},
+	} {
+		t.Run(
+			tt.err, func(t *testing.T) {
+				_, err := internal.Generate(context.Background(), newRequest(opts.SQLEngineSQLite, catalog, tt.options))
+				require.ErrorContains(t, err, tt.err)
+			},
+		)
+	}
+}
+
+func TestGenerateMethodNameClashes(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		values  []string
+		columns []string
+		err     string
+	}{
+		{
+			name:   "empty enum value",
+			values: []string{"", "active"},
+			err:    `the fixture of the table "public.users" gets two Status methods: the setter of the status column and the setter of the "" value of the status column`,
+		},
+		{
+			name:   "enum values differing in case",
+			values: []string{"active", "Active"},
+			err:    `the fixture of the table "public.users" gets two StatusActive methods`,
+		},
+		{
+			name:    "column named like an enum value setter",
+			values:  []string{"active"},
+			columns: []string{"status_active bool not null"},
+			err:     `gets two StatusActive methods: the setter of the "active" value of the status column and the setter of the status_active column`,
+		},
+		{
+			name:    "column named like a fixture method",
+			values:  []string{"active"},
+			columns: []string{"insert text not null"},
+			err:     `gets two Insert methods: the Insert method of the fixture and the setter of the insert column`,
+		},
+		{
+			name:    "column named like a method of the fixtures with a primary key",
+			values:  []string{"active"},
+			columns: []string{"update text not null"},
+			err:     `gets two Update methods: the Update method of the fixture and the setter of the update column`,
+		},
+		{
+			name:    "column named like an assertion",
+			values:  []string{"active"},
+			columns: []string{"assert_exists bool not null"},
+			err:     `gets two AssertExists methods: the AssertExists method of the fixture and the setter of the assert_exists column`,
+		},
+		{
+			name:    "column named like a composite getter",
+			values:  []string{"active"},
+			columns: []string{"home address not null", "get_home text not null"},
+			err:     `gets two GetHome methods: the getter of the home column and the setter of the get_home column`,
+		},
+	} {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				columns := append([]string{"id bigserial not null", "status user_status not null"}, tt.columns...)
+				catalog := &plugin.Catalog{
+					DefaultSchema: "public",
+					Schemas: []*plugin.Schema{
+						{
+							Name:           "public",
+							Enums:          []*plugin.Enum{{Name: "user_status", Vals: tt.values}},
+							CompositeTypes: []*plugin.CompositeType{{Name: "address"}},
+							Tables:         []*plugin.Table{table("public", "users", columns...)},
+						},
+					},
+				}
+				options := `{"package": "fixture", "model_import": "` + modelImport + `", "sql_package": "database/sql",` +
+					` "composite_types": ["address(street text)"]}`
+				_, err := internal.Generate(context.Background(), newRequest(opts.SQLEnginePostgresql, catalog, options))
+				require.ErrorContains(t, err, tt.err)
+			},
+		)
+	}
+}
@@ -35,6 +35,16 @@ func (t *MysqlTypeTransformer) ToGoType(col *plugin.Column) gotype.GoType {
 	return resType
 }
 
+// EnumValues returns the values of the enum type of the column and the Go type of a single value.
+func (t *MysqlTypeTransformer) EnumValues(col *plugin.Column) ([]string, *gotype.GoType) {
+	for _, customType := range t.customTypes {
+		if customType.Kind == sqltype.EnumType && !customType.IsNullable && col.Type.Name == customType.SqlTypeName {
+			return customType.Values, &customType.GoType
+		}
+	}
+	return nil, nil
+}
+
 func (t *MysqlTypeTransformer) getCustomGoType(
 	col *plugin.Column,
 	notNull bool,
@@ -48,12 +48,23 @@ func (t *PostgresqlTypeTransformer) ToGoType(col *plugin.Column) gotype.GoType {
 	return resType
 }
 
+// IsGenerated reports whether the column is of a serial type filled by a sequence.
+func (t *PostgresqlTypeTransformer) IsGenerated(col *plugin.Column) bool {
+	switch sdk.DataType(col.Type) {
+	case "serial", "serial4", "pg_catalog.serial4",
+		"bigserial", "serial8", "pg_catalog.serial8",
+		"smallserial", "serial2", "pg_catalog.serial2":
+		return true
+	}
+	return false
+}
+
 func (t *PostgresqlTypeTransformer) addImport(goType gotype.GoType, driver opts.SQLDriver) gotype.GoType {
 	if goType.PackageName() == "" {
 		return goType
 	}
 	switch goType.PackageName() {
-	case "pgtype":
+	case "pgtype", "pqtype":
 		return t.addPgTypeImports(goType, driver)
 	case "pq":
 		return *goType.SetImport(
@@ -104,6 +115,21 @@ func (t *PostgresqlTypeTransformer) addPgTypeImports(goType gotype.GoType, drive
 	return goType
 }
 
+// EnumValues returns the values of the enum type of the column and the Go type of a single value.
+func (t *PostgresqlTypeTransformer) EnumValues(col *plugin.Column) ([]string, *gotype.GoType) {
+	colSchema := col.Type.Schema
+	if colSchema == "" {
+		colSchema = t.defaultSchema
+	}
+	for _, customType := range t.customTypes {
+		if customType.Kind == sqltype.EnumType && !customType.IsNullable &&
+			colSchema == customType.Schema && col.Type.Name == customType.SqlTypeName {
+			return customType.Values, &customType.GoType
+		}
+	}
+	return nil, nil
+}
+
 func (t *PostgresqlTypeTransformer) getCustomGoType(
 	col *plugin.Column,
 	notNull bool,
@@ -117,6 +117,19 @@ type DbTOGoTypeTransformer interface {
 	ToGoType(col *plugin.Column) GoType
 }
 
+// GeneratedColumnDetector is implemented by the transformers of the engines
+// having the column types which values are generated by the database, like serial in PostgreSQL.
+type GeneratedColumnDetector interface {
+	IsGenerated(col *plugin.Column) bool
+}
+
+// EnumDetector is implemented by the transformers of the engines supporting enum types.
+type EnumDetector interface {
+	// EnumValues returns the values of the enum type of the column
+	// and the Go type of a single value, e.g. Status for the NullStatus field.
+	EnumValues(col *plugin.Column) ([]string, *GoType)
+}
+
 type GoTypeFormatter struct {
 	defaultSchema      string
 	sqlTypeTransformer DbTOGoTypeTransformer
@@ -155,6 +168,33 @@ func (f *GoTypeFormatter) ToGoType(col *plugin.Column) GoType {
 	return gotype
 }
 
+// IsGenerated reports whether the value of the column is generated by the database according to its type.
+func (f *GoTypeFormatter) IsGenerated(col *plugin.Column) bool {
+	detector, ok := f.sqlTypeTransformer.(GeneratedColumnDetector)
+	return ok && detector.IsGenerated(col)
+}
+
+// EnumValues returns the values of the enum type of the column and the Go type of a single value.
+// The columns of the overridden types and the arrays have no values.
+func (f *GoTypeFormatter) EnumValues(col *plugin.Column) ([]string, *GoType) {
+	if _, overridden := f.overriddenType(col); overridden || col.IsArray || col.IsSqlcSlice {
+		return nil, nil
+	}
+	detector, ok := f.sqlTypeTransformer.(EnumDetector)
+	if !ok {
+		return nil, nil
+	}
+	values, goType := detector.EnumValues(col)
+	if goType == nil {
+		return nil, nil
+	}
+	valueType := *goType
+	if valueType.packageName != "" && valueType.typeImport.Path == "" {
+		valueType = f.addImport(valueType)
+	}
+	return values, &valueType
+}
+
 func (f *GoTypeFormatter) addImport(goType GoType) GoType {
 	if goType.PackageName() == "" {
 		return goType
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"github.com/debugger84/sqlc-fixture/internal/gotype"
+	"github.com/debugger84/sqlc-fixture/internal/naming"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"strings"
+)
+
+// Composite is the Go struct generated for a PostgreSQL composite type.
+// sqlc maps the composite columns to strings, so the struct is encoded to the text format of the type.
+// The name of the struct has the Composite suffix, so it does not collide with the models
+// when the fixtures are generated in the models package.
+type Composite struct {
+	name   string
+	fields []CompositeField
+}
+
+// CompositeField is a field of the struct of a composite type.
+type CompositeField struct {
+	// Name is the name of the field in Go.
+	Name string
+	// DBName is the name of the attribute of the composite type.
+	DBName string
+	// Type is the Go type of the field. The attributes of the types without the text parser are kept as strings.
+	Type string
+}
+
+var compositeGoTypes = map[string]string{
+	"bool":             "bool",
+	"boolean":          "bool",
+	"int2":             "int16",
+	"smallint":         "int16",
+	"int4":             "int32",
+	"int":              "int32",
+	"integer":          "int32",
+	"int8":             "int64",
+	"bigint":           "int64",
+	"float4":           "float32",
+	"real":             "float32",
+	"float8":           "float64",
+	"double precision": "float64",
+}
+
+func newComposite(compositeType opts.CompositeType, schema string, normalizer *naming.NameNormalizer) *Composite {
+	composite := &Composite{
+		name: normalizer.NormalizeGoType(normalizer.NormalizeSqlName(schema, compositeType.Name)) + "Composite",
+	}
+	for _, attribute := range compositeType.Attributes {
+		sqlType := strings.TrimPrefix(strings.ToLower(attribute.Type), "pg_catalog.")
+		if i := strings.Index(sqlType, "("); i >= 0 {
+			sqlType = strings.TrimSpace(sqlType[:i])
+		}
+		goType, ok := compositeGoTypes[sqlType]
+		if !ok {
+			goType = "string"
+		}
+		composite.fields = append(
+			composite.fields,
+			CompositeField{Name: normalizer.NormalizeGoType(attribute.Name), DBName: attribute.Name, Type: goType},
+		)
+	}
+	return composite
+}
+
+func (c *Composite) Name() string {
+	return c.name
+}
+
+func (c *Composite) Fields() []CompositeField {
+	return c.fields
+}
+
+// compositeOf returns the struct of the composite type of the column
+// if sqlc maps the column to a string and the type is configured in the composite_types option.
+func compositeOf(
+	column *plugin.Column,
+	goType *gotype.GoType,
+	options *opts.Options,
+	normalizer *naming.NameNormalizer,
+) *Composite {
+	switch goType.String() {
+	case "string", "sql.NullString", "*string":
+	default:
+		return nil
+	}
+	if column.GetIsArray() || column.GetIsSqlcSlice() || column.GetType() == nil {
+		return nil
+	}
+	schema := column.GetType().GetSchema()
+	if schema == "" {
+		schema = options.DefaultSchema
+	}
+	compositeType, ok := opts.FindCompositeType(options.Composites, schema, column.GetType().GetName())
+	if !ok {
+		return nil
+	}
+	return newComposite(compositeType, schema, normalizer)
+}
@@ -1,12 +1,13 @@
 package model
 
 import (
+	"fmt"
 	"github.com/debugger84/sqlc-fixture/internal/gotype"
 	"github.com/debugger84/sqlc-fixture/internal/gotype/db"
+	"github.com/debugger84/sqlc-fixture/internal/naming"
 	"github.com/debugger84/sqlc-fixture/internal/opts"
 	"github.com/debugger84/sqlc-fixture/internal/sqltype"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
-	"log"
 	"sort"
 )
 
@@ -14,15 +15,15 @@ func BuildStructs(
 	req *plugin.GenerateRequest,
 	options *opts.Options,
 	customTypes []sqltype.CustomType,
-) []Struct {
+) ([]Struct, error) {
 	var structs []Struct
 
 	gotypeTransformer, err := db.NewDbTOGoTypeTransformer(opts.SQLEngine(req.Settings.Engine), customTypes, options)
 	if err != nil {
-		log.Fatal(err)
-		return nil
+		return nil, err
 	}
 	goTypeFormatter := gotype.NewGoTypeFormatter(gotypeTransformer, options)
+	tables := make(map[string][]*plugin.Table)
 	for _, schema := range req.Catalog.Schemas {
 		if schema.Name == "pg_catalog" || schema.Name == "information_schema" {
 			continue
@@ -30,10 +31,47 @@ func BuildStructs(
 		for _, table := range schema.Tables {
 			s := NewStruct(table, options, goTypeFormatter)
 			structs = append(structs, *s)
+			tables[s.Type().TypeName()] = append(tables[s.Type().TypeName()], table)
 		}
 	}
+	if err := validateExcludedFields(tables, options); err != nil {
+		return nil, fmt.Errorf("invalid exclude: %w", err)
+	}
 	if len(structs) > 0 {
 		sort.Slice(structs, func(i, j int) bool { return structs[i].Type().TypeName() < structs[j].Type().TypeName() })
 	}
-	return structs
+	if err := linkRelations(structs, options); err != nil {
+		return nil, fmt.Errorf("invalid relations: %w", err)
+	}
+	for _, s := range structs {
+		if err := s.checkMethodNames(); err != nil {
+			return nil, err
+		}
+	}
+	return structs, nil
+}
+
+// validateExcludedFields checks that every excluded field of a table struct names a column of the table,
+// so a misspelled field is reported instead of being generated.
+// The items naming other structs, e.g. the params of queries, are skipped.
+func validateExcludedFields(tables map[string][]*plugin.Table, options *opts.Options) error {
+	normalizer := naming.NewNameNormalizer(options)
+	for _, excluded := range options.ExcludedFields {
+		structTables, ok := tables[excluded.Struct]
+		if !ok {
+			continue
+		}
+		found := false
+		for _, table := range structTables {
+			for _, column := range table.Columns {
+				if excluded.Matches(excluded.Struct, normalizer.NormalizeGoType(column.Name), column.Name) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("field %q is not found", excluded.Struct+"."+excluded.Field)
+		}
+	}
+	return nil
 }
@@ -1,8 +1,12 @@
 package model
 
 import (
+	"fmt"
 	"github.com/debugger84/sqlc-fixture/internal/gotype"
+	"github.com/debugger84/sqlc-fixture/internal/naming"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"strconv"
 )
 
 type Field struct {
@@ -14,6 +18,18 @@ type Field struct {
 	column  *plugin.Column
 
 	isPrimaryKey bool
+	isNaturalKey bool
+	isGenerated  bool
+
+	// defaultValue is the Go expression configured in default_type_values for the field type,
+	// the first value of the enum type or the generated fake value if the emit_fake_data option is on.
+	defaultValue *opts.DefaultTypeValue
+
+	// enumValues are the values of the enum type of the field.
+	enumValues []EnumValue
+
+	// composite is the struct of the composite type of the field.
+	composite *Composite
 
 	// EmbedFields contains the embedded fields that require scanning.
 	embedFields []Field
@@ -50,3 +66,71 @@ func (f *Field) EmbedFields() []Field {
 func (f *Field) IsPrimaryKey() bool {
 	return f.isPrimaryKey
 }
+
+// IsNaturalKey reports whether the field is a part of the natural key configured
+// for a table without a primary key.
+func (f *Field) IsNaturalKey() bool {
+	return f.isNaturalKey
+}
+
+// IsGenerated reports whether the value of the field is generated by the database,
+// so the field is left out of INSERT if it has the zero value.
+func (f *Field) IsGenerated() bool {
+	return f.isGenerated
+}
+
+// EnumValues returns the values of the enum type of the field in the order of declaration.
+func (f *Field) EnumValues() []EnumValue {
+	return f.enumValues
+}
+
+// Composite returns the struct of the composite type of the field or nil if the field is not of a composite type.
+func (f *Field) Composite() *Composite {
+	return f.composite
+}
+
+// DefaultValue returns the Go expression that fills the field if it has the zero value.
+// It is empty if no default value is configured for the field type and no fake value can be generated.
+func (f *Field) DefaultValue() string {
+	if f.defaultValue == nil {
+		return ""
+	}
+	return f.defaultValue.Value
+}
+
+// EnumValue is a value of the enum type of a field.
+type EnumValue struct {
+	// Name is the name of the fixture setter assigning the value, e.g. StatusActive.
+	Name string
+	// Value is the value in the database.
+	Value string
+	// Expr is the Go expression of the field value.
+	Expr string
+}
+
+// newEnumValues returns the values of the enum type of the field.
+// The field of a nullable enum gets the valid values of its type, e.g. NullStatus{Status: "active", Valid: true}.
+func newEnumValues(
+	fieldName string,
+	values []string,
+	goType *gotype.GoType,
+	valueType *gotype.GoType,
+	normalizer *naming.NameNormalizer,
+) []EnumValue {
+	if valueType == nil || goType.IsPointer() {
+		return nil
+	}
+	enumValues := make([]EnumValue, len(values))
+	for i, value := range values {
+		expr := fmt.Sprintf("%s(%s)", valueType.String(), strconv.Quote(value))
+		if goType.TypeName() != valueType.TypeName() {
+			expr = fmt.Sprintf("%s{%s: %s, Valid: true}", goType.String(), valueType.TypeName(), expr)
+		}
+		enumValues[i] = EnumValue{
+			Name:  fieldName + normalizer.NormalizeGoType(value),
+			Value: value,
+			Expr:  expr,
+		}
+	}
+	return enumValues
+}
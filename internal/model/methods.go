@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+)
+
+// commonMethodNames are the names of the methods of every fixture of a table.
+var commonMethodNames = []string{
+	"InTx",
+	"InSession",
+	"GetEntity",
+	"Create",
+	"CreateCtx",
+	"CreateMany",
+	"CreateManyCtx",
+	"Insert",
+	"InsertMany",
+	"Cleanup",
+}
+
+// primaryKeyMethodNames are the names of the methods of the fixtures of the tables with a primary key.
+var primaryKeyMethodNames = []string{
+	"PullUpdates",
+	"PullUpdatesCtx",
+	"Reload",
+	"PushUpdates",
+	"PushUpdatesCtx",
+	"Update",
+	"AssertExists",
+	"AssertNotExists",
+	"AssertMatches",
+}
+
+// fixtureMethods collects the names of the methods generated for the fixture of a struct
+// with the descriptions of their origins to report the methods getting the same name.
+type fixtureMethods struct {
+	table   string
+	origins map[string]string
+}
+
+func (m *fixtureMethods) add(name string, origin string) error {
+	if other, ok := m.origins[name]; ok {
+		return fmt.Errorf(
+			"the fixture of the table %q gets two %s methods: %s and %s, rename one of them with the rename option",
+			m.table,
+			name,
+			other,
+			origin,
+		)
+	}
+	m.origins[name] = origin
+	return nil
+}
+
+// checkMethodNames checks that the methods generated for the fixture of the struct get different names,
+// e.g. the setter of the `status_active` column and the setter of the `active` value of the `status` enum column
+// or the setter of the `update` column and the Update method.
+// The fixture of a view has no setters.
+func (s *Struct) checkMethodNames() error {
+	if s.isView {
+		return nil
+	}
+	methods := fixtureMethods{table: s.FullTableName(), origins: map[string]string{}}
+	names := commonMethodNames
+	if s.hasPrimaryKey {
+		names = append(names[:len(names):len(names)], primaryKeyMethodNames...)
+	}
+	for _, name := range names {
+		if err := methods.add(name, fmt.Sprintf("the %s method of the fixture", name)); err != nil {
+			return err
+		}
+	}
+	for _, relation := range s.relations {
+		if err := methods.add("With"+relation.Name(), fmt.Sprintf("the setter of the %s relation", relation.Name())); err != nil {
+			return err
+		}
+	}
+	for _, key := range s.uniqueKeys {
+		for _, name := range []string{"FindBy" + key.Name(), "FindBy" + key.Name() + "Ctx"} {
+			if err := methods.add(name, fmt.Sprintf("the %s lookup of the fixture", name)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, field := range s.fields {
+		if err := methods.add(field.Name(), fmt.Sprintf("the setter of the %s column", field.DBName())); err != nil {
+			return err
+		}
+		if field.Composite() != nil {
+			if err := methods.add("Get"+field.Name(), fmt.Sprintf("the getter of the %s column", field.DBName())); err != nil {
+				return err
+			}
+		}
+		for _, value := range field.EnumValues() {
+			origin := fmt.Sprintf("the setter of the %q value of the %s column", value.Value, field.DBName())
+			if err := methods.add(value.Name, origin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"github.com/debugger84/sqlc-fixture/internal/naming"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"strings"
+)
+
+// Relation is a foreign key of the struct table referencing the table of the parent struct.
+type Relation struct {
+	name   string
+	parent *Struct
+	keys   []RelationKey
+}
+
+// RelationKey is a pair of the foreign key field and the field of the parent struct referenced by it.
+type RelationKey struct {
+	Field       Field
+	ParentField Field
+}
+
+// Name returns the name of the relation derived from the last foreign key column,
+// e.g. `Author` for the `author_id` column.
+func (r *Relation) Name() string {
+	return r.name
+}
+
+func (r *Relation) Parent() *Struct {
+	return r.parent
+}
+
+func (r *Relation) Keys() []RelationKey {
+	return r.keys
+}
+
+// IsRequired reports whether all the foreign key columns are NOT NULL,
+// so the parent record should exist before the insert.
+func (r *Relation) IsRequired() bool {
+	for _, key := range r.keys {
+		if !key.Field.Column().GetNotNull() {
+			return false
+		}
+	}
+	return true
+}
+
+// linkRelations adds the relations configured in the options to the structs of the related tables.
+func linkRelations(structs []Struct, options *opts.Options) error {
+	normalizer := naming.NewNameNormalizer(options)
+	for _, foreignKey := range options.ForeignKeys {
+		child := findStruct(structs, foreignKey.Columns)
+		if child == nil {
+			return fmt.Errorf("table of the relation columns %v is not found", foreignKey.Columns.Columns)
+		}
+		parent := findStruct(structs, foreignKey.References)
+		if parent == nil {
+			return fmt.Errorf("table of the relation references %v is not found", foreignKey.References.Columns)
+		}
+		if child.isView || parent.isView {
+			return fmt.Errorf("relation %v -> %v links a view, that is read-only", foreignKey.Columns.Columns, foreignKey.References.Columns)
+		}
+
+		relation := Relation{parent: parent}
+		for i, column := range foreignKey.Columns.Columns {
+			field := child.fieldByDBName(column)
+			if field == nil {
+				return fmt.Errorf("column %q is not found in the table %q", column, child.FullTableName())
+			}
+			parentField := parent.fieldByDBName(foreignKey.References.Columns[i])
+			if parentField == nil {
+				return fmt.Errorf(
+					"column %q is not found in the table %q",
+					foreignKey.References.Columns[i],
+					parent.FullTableName(),
+				)
+			}
+			relation.keys = append(relation.keys, RelationKey{Field: *field, ParentField: *parentField})
+		}
+
+		lastColumn := foreignKey.Columns.Columns[len(foreignKey.Columns.Columns)-1]
+		name := strings.TrimSuffix(strings.TrimSuffix(lastColumn, "_id"), "_ID")
+		if name == "" || strings.EqualFold(name, "id") {
+			relation.name = parent.Type().TypeName()
+		} else {
+			relation.name = normalizer.NormalizeGoType(name)
+		}
+		child.relations = append(child.relations, relation)
+	}
+	return nil
+}
+
+func findStruct(structs []Struct, columns opts.ColumnSet) *Struct {
+	for i := range structs {
+		if columns.Matches(structs[i].table.Rel.GetSchema(), structs[i].table.Rel.GetName()) {
+			return &structs[i]
+		}
+	}
+	return nil
+}
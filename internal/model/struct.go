@@ -2,12 +2,14 @@ package model
 
 import (
 	"fmt"
+	"github.com/debugger84/sqlc-fixture/internal/fakedata"
 	gotype "github.com/debugger84/sqlc-fixture/internal/gotype"
 	"github.com/debugger84/sqlc-fixture/internal/imports"
 	"github.com/debugger84/sqlc-fixture/internal/inflection"
 	"github.com/debugger84/sqlc-fixture/internal/naming"
 	"github.com/debugger84/sqlc-fixture/internal/opts"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"slices"
 	"strings"
 )
 
@@ -15,9 +17,16 @@ type Struct struct {
 	table         *plugin.Table
 	tableName     string
 	structName    string
+	pluralName    string
 	fields        []Field
 	hasPrimaryKey bool
 	goType        *gotype.GoType
+	defaultSchema string
+	relations     []Relation
+	uniqueKeys    []UniqueKey
+	cleanup       opts.TableCleanup
+	view          opts.View
+	isView        bool
 }
 
 func NewStruct(
@@ -27,11 +36,15 @@ func NewStruct(
 ) *Struct {
 	nameNormalizer := naming.NewNameNormalizer(options)
 	s := &Struct{
-		table: table,
+		table:         table,
+		defaultSchema: options.DefaultSchema,
+		cleanup:       opts.FindTableCleanup(options.Cleanup, table.Rel.GetSchema(), table.Rel.GetName()),
 	}
+	s.view, s.isView = opts.FindView(options.ViewTables, table.Rel.GetSchema(), table.Rel.GetName())
 
 	s.initNames(table, options, nameNormalizer)
 	s.initFields(table, options, nameNormalizer, goTypeFormatter)
+	s.initUniqueKeys(options)
 
 	return s
 }
@@ -60,12 +73,19 @@ func (s *Struct) initNames(
 		structName = options.ModelImport + "." + structName
 	}
 	s.goType = gotype.NewGoType(structName)
+	s.pluralName = normalizer.NormalizeGoType(s.tableName)
 }
 
 func (s *Struct) TableName() string {
 	return s.tableName
 }
 
+// PluralName returns the Go name of the table used by the functions working with all its rows,
+// e.g. `Users` for the `users` table.
+func (s *Struct) PluralName() string {
+	return s.pluralName
+}
+
 func (s *Struct) FullTableName() string {
 	schema := s.table.Rel.GetSchema()
 	tableName := s.table.Rel.GetName()
@@ -77,6 +97,21 @@ func (s *Struct) FullTableName() string {
 	return fmt.Sprintf("%s.%s", schema, tableName)
 }
 
+// DBName returns the name of the table in the database without the schema.
+func (s *Struct) DBName() string {
+	return s.table.Rel.GetName()
+}
+
+// SchemaName returns the schema of the table or an empty string if the table has no schema
+// or is in the default schema.
+func (s *Struct) SchemaName() string {
+	schema := s.table.Rel.GetSchema()
+	if schema == s.defaultSchema {
+		return ""
+	}
+	return schema
+}
+
 func (s *Struct) Type() *gotype.GoType {
 	return s.goType
 }
@@ -92,6 +127,11 @@ func (s *Struct) GetImports() []imports.Import {
 		}
 		allImports = append(allImports, field.goType.Import())
 	}
+	for _, field := range s.fields {
+		if field.defaultValue != nil && field.defaultValue.Import != "" {
+			allImports = append(allImports, imports.Import{Path: field.defaultValue.Import})
+		}
+	}
 	if s.goType == nil {
 		return allImports
 	}
@@ -107,31 +147,46 @@ func (s *Struct) initFields(
 	normalizer *naming.NameNormalizer,
 	goTypeFormatter *gotype.GoTypeFormatter,
 ) {
-	primaryKeyColumn := "id"
-	for _, column := range options.PrimaryKeysColumns {
-		parts := strings.Split(column, ".")
-		if len(parts) == 2 && parts[0] == table.Rel.GetName() {
-			primaryKeyColumn = parts[1]
-			break
-		}
+	primaryKeyColumns := opts.TableColumns(options.PrimaryKeys, table.Rel.GetSchema(), table.Rel.GetName())
+	if len(primaryKeyColumns) == 0 {
+		primaryKeyColumns = []string{"id"}
 	}
+	naturalKeyColumns := opts.TableColumns(options.NaturalKeys, table.Rel.GetSchema(), table.Rel.GetName())
+	generatedColumns := opts.TableColumns(options.Generated, table.Rel.GetSchema(), table.Rel.GetName())
 	for _, column := range table.Columns {
+		name := normalizer.NormalizeGoType(column.Name)
+		if opts.IsExcludedField(options.ExcludedFields, s.goType.TypeName(), name, column.Name) {
+			continue
+		}
 		tags := map[string]string{}
 		isPrimaryKey := false
-		if column.Name == primaryKeyColumn {
+		if slices.Contains(primaryKeyColumns, column.Name) {
 			isPrimaryKey = true
 			s.hasPrimaryKey = true
 		}
 		goType := goTypeFormatter.ToGoType(column)
+		isGenerated := !s.isView && (slices.Contains(generatedColumns, column.Name) || goTypeFormatter.IsGenerated(column))
+		values, valueType := goTypeFormatter.EnumValues(column)
+		enumValues := newEnumValues(name, values, &goType, valueType, normalizer)
+		composite := compositeOf(column, &goType, options, normalizer)
+		var value *opts.DefaultTypeValue
+		if !isGenerated && !s.isView {
+			value = defaultValue(column, &goType, enumValues, composite, options)
+		}
 		s.fields = append(
 			s.fields, Field{
-				name:         normalizer.NormalizeGoType(column.Name),
+				name:         name,
 				dBName:       column.Name,
 				goType:       &goType,
 				tags:         tags,
 				comment:      column.Comment,
 				column:       column,
 				isPrimaryKey: isPrimaryKey,
+				isNaturalKey: slices.Contains(naturalKeyColumns, column.Name),
+				isGenerated:  isGenerated,
+				defaultValue: value,
+				enumValues:   enumValues,
+				composite:    composite,
 			},
 		)
 	}
@@ -144,3 +199,167 @@ func (s *Struct) Fields() []Field {
 func (s *Struct) HasPrimaryKey() bool {
 	return s.hasPrimaryKey
 }
+
+// HasDefaultValues reports whether any field has a default value configured for its type.
+func (s *Struct) HasDefaultValues() bool {
+	for _, field := range s.fields {
+		if field.defaultValue != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGeneratedFields reports whether any field is generated by the database.
+func (s *Struct) HasGeneratedFields() bool {
+	for _, field := range s.fields {
+		if field.isGenerated {
+			return true
+		}
+	}
+	return false
+}
+
+// Cleanup returns the strategy of deleting the rows created by the fixture.
+func (s *Struct) Cleanup() opts.TableCleanup {
+	return s.cleanup
+}
+
+// Relations returns the foreign keys configured for the table in the relations option.
+func (s *Struct) Relations() []Relation {
+	return s.relations
+}
+
+// RequiredRelations returns the relations with NOT NULL foreign keys
+// except the ones referencing the same table.
+func (s *Struct) RequiredRelations() []Relation {
+	relations := make([]Relation, 0)
+	for _, relation := range s.relations {
+		if relation.IsRequired() && relation.parent.table != s.table {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// IsView reports whether the table is a view, so its fixture only reads the rows.
+func (s *Struct) IsView() bool {
+	return s.isView
+}
+
+// IsMaterializedView reports whether the table is a materialized view that can be refreshed.
+func (s *Struct) IsMaterializedView() bool {
+	return s.isView && s.view.Materialized
+}
+
+// UniqueKeys returns the unique keys configured for the table in the unique_columns option.
+func (s *Struct) UniqueKeys() []UniqueKey {
+	return s.uniqueKeys
+}
+
+// Depth returns the length of the longest chain of relations from the table to a table referencing no other tables.
+// The rows of the deeper tables should be deleted first. The relations of a table to itself are ignored.
+func (s *Struct) Depth() int {
+	return s.depth(map[*plugin.Table]bool{})
+}
+
+func (s *Struct) depth(visiting map[*plugin.Table]bool) int {
+	if visiting[s.table] {
+		return 0
+	}
+	visiting[s.table] = true
+	defer delete(visiting, s.table)
+	depth := 0
+	for _, relation := range s.relations {
+		if relation.parent.table == s.table {
+			continue
+		}
+		if d := relation.parent.depth(visiting) + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func (s *Struct) fieldByDBName(name string) *Field {
+	for i := range s.fields {
+		if s.fields[i].dBName == name {
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
+
+// PrimaryKeyFields returns all the fields of the primary key in the order of the table columns.
+func (s *Struct) PrimaryKeyFields() []Field {
+	fields := make([]Field, 0, 1)
+	for _, field := range s.fields {
+		if field.isPrimaryKey {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// NaturalKeyFields returns the fields of the natural key configured for the table.
+func (s *Struct) NaturalKeyFields() []Field {
+	fields := make([]Field, 0)
+	for _, field := range s.fields {
+		if field.isNaturalKey {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// defaultValue returns the default value configured for the field type.
+// If there is no such value, a NOT NULL enum gets its first value.
+// Otherwise, if the emit_fake_data option is on, a generated fake value is used.
+// A NOT NULL composite gets the text of the zero struct of its type,
+// because the values configured for strings are not valid composite values.
+func defaultValue(
+	column *plugin.Column,
+	goType *gotype.GoType,
+	enumValues []EnumValue,
+	composite *Composite,
+	options *opts.Options,
+) *opts.DefaultTypeValue {
+	if composite != nil {
+		if !column.GetNotNull() || goType.String() != "string" {
+			return nil
+		}
+		return &opts.DefaultTypeValue{Type: goType.String(), Value: composite.Name() + "{}.String()"}
+	}
+	if value := findDefaultTypeValue(options.DefaultTypeValues, goType); value != nil {
+		return value
+	}
+	if len(enumValues) > 0 {
+		if !column.GetNotNull() {
+			return nil
+		}
+		return &opts.DefaultTypeValue{Type: goType.String(), Value: enumValues[0].Expr}
+	}
+	if !options.EmitFakeData {
+		return nil
+	}
+	if expr := fakedata.Expression(column, goType); expr != "" {
+		return &opts.DefaultTypeValue{Type: goType.String(), Value: expr}
+	}
+	return nil
+}
+
+// findDefaultTypeValue returns the default value configured for the Go type.
+// The type can be configured with a package name, e.g. `uuid.UUID`,
+// or with a full import path, e.g. `github.com/gofrs/uuid.UUID`.
+func findDefaultTypeValue(values []opts.DefaultTypeValue, goType *gotype.GoType) *opts.DefaultTypeValue {
+	fullName := goType.String()
+	if path := goType.Import().Path; path != "" {
+		fullName = strings.Replace(fullName, goType.PackageName()+".", path+".", 1)
+	}
+	for i, value := range values {
+		if value.Type == goType.String() || value.Type == fullName {
+			return &values[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"strings"
+)
+
+// UniqueKey is a group of columns configured in the unique_columns option
+// that identifies a row of the table without its primary key.
+type UniqueKey struct {
+	fields []Field
+}
+
+// Name returns the name of the key made of the names of its fields,
+// e.g. `Email` for the `email` column or `TenantIDNumber` for the `tenant_id` and `number` columns.
+func (k *UniqueKey) Name() string {
+	names := make([]string, len(k.fields))
+	for i, field := range k.fields {
+		names[i] = field.Name()
+	}
+	return strings.Join(names, "")
+}
+
+// Fields returns the fields of the key in the order of the columns in the option.
+func (k *UniqueKey) Fields() []Field {
+	return k.fields
+}
+
+// initUniqueKeys adds the unique keys configured for the table.
+// The keys with the excluded fields are skipped, because the fixture cannot select them.
+func (s *Struct) initUniqueKeys(options *opts.Options) {
+	for _, set := range options.UniqueKeys {
+		if !set.Matches(s.table.Rel.GetSchema(), s.table.Rel.GetName()) {
+			continue
+		}
+		key := UniqueKey{fields: make([]Field, 0, len(set.Columns))}
+		for _, column := range set.Columns {
+			if field := s.fieldByDBName(column); field != nil {
+				key.fields = append(key.fields, *field)
+			}
+		}
+		if len(key.fields) == len(set.Columns) {
+			s.uniqueKeys = append(s.uniqueKeys, key)
+		}
+	}
+}
+
+// ViewKeys returns the keys the fixture of a view finds the rows by:
+// the primary key if the view has it and the unique keys.
+func (s *Struct) ViewKeys() []UniqueKey {
+	keys := make([]UniqueKey, 0, len(s.uniqueKeys)+1)
+	if s.hasPrimaryKey {
+		keys = append(keys, UniqueKey{fields: s.PrimaryKeyFields()})
+	}
+	return append(keys, s.uniqueKeys...)
+}
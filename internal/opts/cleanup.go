@@ -0,0 +1,95 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+type CleanupStrategy string
+
+const (
+	// CleanupDelete deletes the created rows by their keys.
+	CleanupDelete CleanupStrategy = "delete"
+	// CleanupNone leaves the created rows in the table.
+	CleanupNone CleanupStrategy = "none"
+	// CleanupTruncateCascade truncates the table and the tables referencing it.
+	CleanupTruncateCascade CleanupStrategy = "truncate_cascade"
+	// CleanupSoftDelete sets the soft delete column of the created rows to the current time.
+	CleanupSoftDelete CleanupStrategy = "soft_delete"
+	// CleanupDeleteWhere deletes the rows matching the custom predicate.
+	CleanupDeleteWhere CleanupStrategy = "delete_where"
+)
+
+var validCleanupStrategies = map[CleanupStrategy]struct{}{
+	CleanupDelete:          {},
+	CleanupNone:            {},
+	CleanupTruncateCascade: {},
+	CleanupSoftDelete:      {},
+	CleanupDeleteWhere:     {},
+}
+
+const defaultSoftDeleteColumn = "deleted_at"
+
+// TableCleanup is the strategy of deleting the rows created by the fixtures of a table.
+type TableCleanup struct {
+	// Table is written as `tablename` or `schema.tablename`.
+	Table    string          `json:"table" yaml:"table"`
+	Strategy CleanupStrategy `json:"strategy" yaml:"strategy"`
+	// Column is the column set by the soft_delete strategy, `deleted_at` by default.
+	Column string `json:"column" yaml:"column"`
+	// Where is the predicate of the delete_where strategy.
+	Where string `json:"where" yaml:"where"`
+	// ResetSequences restarts the auto-increment sequence of the primary key after deleting.
+	ResetSequences bool `json:"reset_sequences" yaml:"reset_sequences"`
+
+	schema    string
+	tableName string
+}
+
+func (c *TableCleanup) parse() error {
+	parts := strings.Split(c.Table, ".")
+	switch len(parts) {
+	case 1:
+		c.tableName = parts[0]
+	case 2:
+		c.schema, c.tableName = parts[0], parts[1]
+	default:
+		return fmt.Errorf("table %q is not the proper format, expected '[schema.]tablename'", c.Table)
+	}
+	if c.tableName == "" {
+		return fmt.Errorf("table %q is not the proper format, expected '[schema.]tablename'", c.Table)
+	}
+	if c.Strategy == "" {
+		c.Strategy = CleanupDelete
+	}
+	if _, ok := validCleanupStrategies[c.Strategy]; !ok {
+		return fmt.Errorf("unknown cleanup strategy %q of the table %q", c.Strategy, c.Table)
+	}
+	if c.Strategy == CleanupSoftDelete && c.Column == "" {
+		c.Column = defaultSoftDeleteColumn
+	}
+	if c.Strategy == CleanupDeleteWhere && strings.TrimSpace(c.Where) == "" {
+		return fmt.Errorf("the delete_where cleanup strategy of the table %q requires the where predicate", c.Table)
+	}
+	return nil
+}
+
+// Matches reports whether the cleanup belongs to the table.
+// A cleanup without a schema matches the table in any schema.
+func (c TableCleanup) Matches(schema, table string) bool {
+	if c.tableName != table {
+		return false
+	}
+	return c.schema == "" || c.schema == schema
+}
+
+// FindTableCleanup returns the cleanup configured for the table
+// or the delete strategy if there is no such configuration.
+func FindTableCleanup(cleanups []TableCleanup, schema, table string) TableCleanup {
+	for _, cleanup := range cleanups {
+		if cleanup.Matches(schema, table) {
+			return cleanup
+		}
+	}
+	return TableCleanup{Strategy: CleanupDelete}
+}
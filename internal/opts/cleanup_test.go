@@ -0,0 +1,66 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestTableCleanupParse(t *testing.T) {
+	for _, test := range []struct {
+		cleanup  TableCleanup
+		strategy CleanupStrategy
+		column   string
+	}{
+		{TableCleanup{Table: "users"}, CleanupDelete, ""},
+		{TableCleanup{Table: "public.users", Strategy: CleanupNone}, CleanupNone, ""},
+		{TableCleanup{Table: "users", Strategy: CleanupSoftDelete}, CleanupSoftDelete, "deleted_at"},
+		{TableCleanup{Table: "users", Strategy: CleanupSoftDelete, Column: "removed_at"}, CleanupSoftDelete, "removed_at"},
+		{TableCleanup{Table: "users", Strategy: CleanupDeleteWhere, Where: "id > 100"}, CleanupDeleteWhere, ""},
+	} {
+		tt := test
+		t.Run(tt.cleanup.Table+" "+string(tt.cleanup.Strategy), func(t *testing.T) {
+			if err := tt.cleanup.parse(); err != nil {
+				t.Fatalf("cleanup parsing failed; %s", err)
+			}
+			if tt.cleanup.Strategy != tt.strategy {
+				t.Errorf("expected strategy %q, got %q", tt.strategy, tt.cleanup.Strategy)
+			}
+			if tt.cleanup.Column != tt.column {
+				t.Errorf("expected column %q, got %q", tt.column, tt.cleanup.Column)
+			}
+		})
+	}
+	for _, test := range []TableCleanup{
+		{Table: ""},
+		{Table: "a.b.c"},
+		{Table: "users", Strategy: "drop"},
+		{Table: "users", Strategy: CleanupDeleteWhere},
+	} {
+		tt := test
+		t.Run(tt.Table+" "+string(tt.Strategy), func(t *testing.T) {
+			if err := tt.parse(); err == nil {
+				t.Errorf("expected invalid cleanup of %q to fail", tt.Table)
+			}
+		})
+	}
+}
+
+func TestFindTableCleanup(t *testing.T) {
+	cleanups := []TableCleanup{
+		{Table: "audit.logs", Strategy: CleanupNone},
+		{Table: "users", Strategy: CleanupTruncateCascade},
+	}
+	for i := range cleanups {
+		if err := cleanups[i].parse(); err != nil {
+			t.Fatalf("cleanup parsing failed; %s", err)
+		}
+	}
+	if got := FindTableCleanup(cleanups, "audit", "logs").Strategy; got != CleanupNone {
+		t.Errorf("expected none, got %q", got)
+	}
+	if got := FindTableCleanup(cleanups, "public", "logs").Strategy; got != CleanupDelete {
+		t.Errorf("expected delete, got %q", got)
+	}
+	if got := FindTableCleanup(cleanups, "public", "users").Strategy; got != CleanupTruncateCascade {
+		t.Errorf("expected truncate_cascade, got %q", got)
+	}
+}
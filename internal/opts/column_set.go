@@ -0,0 +1,95 @@
+package opts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ColumnSet is a group of columns of one table referenced in the plugin options.
+// A single column is written as `users.id` or `schema.users.id`,
+// a group of columns as `(memberships.tenant_id, memberships.user_id)`.
+type ColumnSet struct {
+	Schema  string
+	Table   string
+	Columns []string
+}
+
+func ParseColumnSet(spec string) (ColumnSet, error) {
+	var set ColumnSet
+	spec = strings.TrimSpace(spec)
+	items := []string{spec}
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		items = strings.Split(spec[1:len(spec)-1], ",")
+	}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		parts := strings.Split(item, ".")
+		var schema, table, column string
+		switch len(parts) {
+		case 2:
+			table, column = parts[0], parts[1]
+		case 3:
+			schema, table, column = parts[0], parts[1], parts[2]
+		default:
+			return set, fmt.Errorf("column specifier %q is not the proper format, expected '[schema.]tablename.colname'", item)
+		}
+		if table == "" || column == "" {
+			return set, fmt.Errorf("column specifier %q is not the proper format, expected '[schema.]tablename.colname'", item)
+		}
+		if set.Table == "" {
+			set.Schema = schema
+			set.Table = table
+		} else if set.Table != table || set.Schema != schema {
+			return set, fmt.Errorf("columns of %q should belong to the same table", spec)
+		}
+		set.Columns = append(set.Columns, column)
+	}
+
+	return set, nil
+}
+
+func ParseColumnSets(specs []string) ([]ColumnSet, error) {
+	sets := make([]ColumnSet, 0, len(specs))
+	for _, spec := range specs {
+		set, err := ParseColumnSet(spec)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// Matches reports whether the set belongs to the table.
+// A set without a schema matches the table in any schema.
+func (c ColumnSet) Matches(schema, table string) bool {
+	if c.Table != table {
+		return false
+	}
+	return c.Schema == "" || c.Schema == schema
+}
+
+// name returns the table of the set as it is written in the options.
+func (c ColumnSet) name() string {
+	if c.Schema == "" {
+		return c.Table
+	}
+	return c.Schema + "." + c.Table
+}
+
+// TableColumns merges the columns of all the sets that belong to the table.
+func TableColumns(sets []ColumnSet, schema, table string) []string {
+	var columns []string
+	for _, set := range sets {
+		if !set.Matches(schema, table) {
+			continue
+		}
+		for _, column := range set.Columns {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
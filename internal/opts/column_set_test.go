@@ -0,0 +1,80 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseColumnSet(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		set  ColumnSet
+	}{
+		{
+			"users.id",
+			ColumnSet{Table: "users", Columns: []string{"id"}},
+		},
+		{
+			"auth.users.id",
+			ColumnSet{Schema: "auth", Table: "users", Columns: []string{"id"}},
+		},
+		{
+			"(memberships.tenant_id, memberships.user_id)",
+			ColumnSet{Table: "memberships", Columns: []string{"tenant_id", "user_id"}},
+		},
+	} {
+		tt := test
+		t.Run(tt.spec, func(t *testing.T) {
+			set, err := ParseColumnSet(tt.spec)
+			if err != nil {
+				t.Fatalf("column set parsing failed; %s", err)
+			}
+			if diff := cmp.Diff(tt.set, set); diff != "" {
+				t.Errorf("column set mismatch;\n%s", diff)
+			}
+		})
+	}
+	for _, test := range []struct {
+		spec string
+		err  string
+	}{
+		{
+			"id",
+			"column specifier \"id\" is not the proper format, expected '[schema.]tablename.colname'",
+		},
+		{
+			"(memberships.tenant_id, users.id)",
+			"columns of \"(memberships.tenant_id, users.id)\" should belong to the same table",
+		},
+	} {
+		tt := test
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseColumnSet(tt.spec)
+			if err == nil {
+				t.Fatalf("expected parse to fail; got nil")
+			}
+			if diff := cmp.Diff(tt.err, err.Error()); diff != "" {
+				t.Errorf("error mismatch;\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	sets, err := ParseColumnSets([]string{
+		"users.name",
+		"memberships.tenant_id",
+		"memberships.user_id",
+		"billing.memberships.id",
+	})
+	if err != nil {
+		t.Fatalf("column sets parsing failed; %s", err)
+	}
+	if diff := cmp.Diff([]string{"tenant_id", "user_id"}, TableColumns(sets, "public", "memberships")); diff != "" {
+		t.Errorf("columns mismatch;\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"tenant_id", "user_id", "id"}, TableColumns(sets, "billing", "memberships")); diff != "" {
+		t.Errorf("columns mismatch;\n%s", diff)
+	}
+}
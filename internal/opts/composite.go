@@ -0,0 +1,131 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompositeType is a PostgreSQL composite type of the composite_types option.
+// The catalog passed to the plugin has only the names of the composite types,
+// so their attributes are configured like in the CREATE TYPE statement, e.g. `address(street text, zip_code int4)`.
+type CompositeType struct {
+	Schema     string
+	Name       string
+	Attributes []CompositeAttribute
+}
+
+// CompositeAttribute is an attribute of a composite type with its SQL type, e.g. `text` or `varchar(100)`.
+type CompositeAttribute struct {
+	Name string
+	Type string
+}
+
+// ParseCompositeType parses the composite type written as `[schema.]typename(attribute type, ...)`.
+func ParseCompositeType(spec string) (CompositeType, error) {
+	var compositeType CompositeType
+	name, rest, found := strings.Cut(strings.TrimSpace(spec), "(")
+	body, ok := enclosedBody(rest)
+	if !found || !ok || strings.TrimSpace(rest[len(body)+1:]) != "" {
+		return compositeType, fmt.Errorf(
+			"composite type specifier %q is not the proper format, expected '[schema.]typename(attribute type, ...)'",
+			spec,
+		)
+	}
+	name = strings.ReplaceAll(strings.TrimSpace(name), `"`, "")
+	parts := strings.Split(name, ".")
+	switch len(parts) {
+	case 1:
+		compositeType.Name = parts[0]
+	case 2:
+		compositeType.Schema, compositeType.Name = parts[0], parts[1]
+	}
+	if compositeType.Name == "" {
+		return compositeType, fmt.Errorf("composite type specifier %q has no proper type name", spec)
+	}
+	for _, definition := range splitTopLevel(body) {
+		fields := strings.Fields(definition)
+		if len(fields) < 2 {
+			return compositeType, fmt.Errorf(
+				"composite type specifier %q has the attribute %q without a type",
+				spec,
+				definition,
+			)
+		}
+		attributeType := strings.Join(fields[1:], " ")
+		if i := strings.Index(strings.ToUpper(attributeType), " COLLATE "); i >= 0 {
+			attributeType = attributeType[:i]
+		}
+		compositeType.Attributes = append(
+			compositeType.Attributes,
+			CompositeAttribute{Name: strings.ReplaceAll(fields[0], `"`, ""), Type: attributeType},
+		)
+	}
+	return compositeType, nil
+}
+
+func ParseCompositeTypes(specs []string) ([]CompositeType, error) {
+	types := make([]CompositeType, 0, len(specs))
+	for _, spec := range specs {
+		compositeType, err := ParseCompositeType(spec)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, compositeType)
+	}
+	return types, nil
+}
+
+// enclosedBody returns the text up to the parenthesis closing the one opened before the text.
+func enclosedBody(s string) (string, bool) {
+	depth := 1
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitTopLevel splits the text by the commas that are not enclosed in parentheses, e.g. of numeric(10, 2).
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// FindCompositeType returns the composite type of the schema with the name.
+// A type configured without a schema matches the type in any schema.
+func FindCompositeType(types []CompositeType, schema, name string) (CompositeType, bool) {
+	for _, compositeType := range types {
+		if compositeType.Name == name && (compositeType.Schema == "" || compositeType.Schema == schema) {
+			return compositeType, true
+		}
+	}
+	return CompositeType{}, false
+}
+
+func (t CompositeType) qualifiedName() string {
+	if t.Schema == "" {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
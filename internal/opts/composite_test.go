@@ -0,0 +1,52 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCompositeTypes(t *testing.T) {
+	types, err := ParseCompositeTypes(
+		[]string{
+			`address(street text COLLATE "C", zip_code int4, price numeric(10, 2))`,
+			` "billing"."money_range" ("from" bigint, "to" bigint) `,
+		},
+	)
+	if err != nil {
+		t.Fatalf("composite types parsing failed; %s", err)
+	}
+	expected := []CompositeType{
+		{
+			Name: "address",
+			Attributes: []CompositeAttribute{
+				{Name: "street", Type: "text"},
+				{Name: "zip_code", Type: "int4"},
+				{Name: "price", Type: "numeric(10, 2)"},
+			},
+		},
+		{
+			Schema: "billing",
+			Name:   "money_range",
+			Attributes: []CompositeAttribute{
+				{Name: "from", Type: "bigint"},
+				{Name: "to", Type: "bigint"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Errorf("composite types mismatch;\n%s", diff)
+	}
+	if _, ok := FindCompositeType(types, "public", "address"); !ok {
+		t.Errorf("expected address to match a type in any schema")
+	}
+	if _, ok := FindCompositeType(types, "public", "money_range"); ok {
+		t.Errorf("expected money_range not to match a type in another schema")
+	}
+
+	for _, spec := range []string{"address", "address(street text", "(street text)", "address(street)", "a.b.c(x int)"} {
+		if _, err := ParseCompositeType(spec); err == nil {
+			t.Errorf("expected invalid composite type specifier %q to fail", spec)
+		}
+	}
+}
@@ -38,7 +38,10 @@ const (
 	SQLDriverGoSQLDriverMySQL SQLDriver = "github.com/go-sql-driver/mysql"
 )
 
-func NewSQLDriver(sqlPackage string) SQLDriver {
+func NewSQLDriver(sqlPackage string, engine SQLEngine) SQLDriver {
+	if engine == SQLEngineMySQL {
+		return SQLDriverGoSQLDriverMySQL
+	}
 	switch sqlPackage {
 	case SQLPackagePGXV4:
 		return SQLDriverPGXV4
@@ -49,16 +52,11 @@ func NewSQLDriver(sqlPackage string) SQLDriver {
 	}
 }
 
-var validDrivers = map[string]struct{}{
-	string(SQLDriverPGXV4):            {},
-	string(SQLDriverPGXV5):            {},
-	string(SQLDriverLibPQ):            {},
-	string(SQLDriverGoSQLDriverMySQL): {},
-}
-
-func validateDriver(sqlDriver string) error {
-	if _, found := validDrivers[sqlDriver]; !found {
-		return fmt.Errorf("unknown SQL driver: %s", sqlDriver)
+// validateEngine checks that the SQL package can connect to the database engine.
+// The pgx packages are the PostgreSQL drivers.
+func validateEngine(sqlPackage string, engine SQLEngine) error {
+	if (sqlPackage == SQLPackagePGXV4 || sqlPackage == SQLPackagePGXV5) && engine != SQLEnginePostgresql {
+		return fmt.Errorf("SQL package %s supports only the postgresql engine, not %s", sqlPackage, engine)
 	}
 	return nil
 }
@@ -0,0 +1,49 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExcludedField is a field omitted from a model struct, written as `Struct.field` like in the golang plugin.
+type ExcludedField struct {
+	Struct string
+	Field  string
+}
+
+func ParseExcludedField(spec string) (ExcludedField, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ExcludedField{}, fmt.Errorf("field specifier %q is not the proper format, expected 'Struct.field'", spec)
+	}
+	return ExcludedField{Struct: parts[0], Field: parts[1]}, nil
+}
+
+func ParseExcludedFields(specs []string) ([]ExcludedField, error) {
+	fields := make([]ExcludedField, 0, len(specs))
+	for _, spec := range specs {
+		field, err := ParseExcludedField(spec)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Matches reports whether the field of the struct is excluded.
+// The field is written as the Go field name in any case, so `User.userId` matches the UserID field,
+// or as the column name, e.g. `User.user_id`.
+func (e ExcludedField) Matches(structName, fieldName, columnName string) bool {
+	return e.Struct == structName && (strings.EqualFold(e.Field, fieldName) || e.Field == columnName)
+}
+
+// IsExcludedField reports whether any of the excluded fields matches the field of the struct.
+func IsExcludedField(fields []ExcludedField, structName, fieldName, columnName string) bool {
+	for _, field := range fields {
+		if field.Matches(structName, fieldName, columnName) {
+			return true
+		}
+	}
+	return false
+}
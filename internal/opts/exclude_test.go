@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExcludedFields(t *testing.T) {
+	fields, err := ParseExcludedFields([]string{"User.createdAt", " UpdateStatusInput.ID "})
+	if err != nil {
+		t.Fatalf("excluded fields parsing failed; %s", err)
+	}
+	expected := []ExcludedField{
+		{Struct: "User", Field: "createdAt"},
+		{Struct: "UpdateStatusInput", Field: "ID"},
+	}
+	if diff := cmp.Diff(expected, fields); diff != "" {
+		t.Errorf("excluded fields mismatch;\n%s", diff)
+	}
+	if !IsExcludedField(fields, "User", "CreatedAt", "created_at") {
+		t.Errorf("expected User.CreatedAt to be excluded")
+	}
+	if IsExcludedField(fields, "Post", "CreatedAt", "created_at") {
+		t.Errorf("expected Post.CreatedAt not to be excluded")
+	}
+
+	for _, tt := range []struct {
+		spec   string
+		field  string
+		column string
+	}{
+		{"User.id", "ID", "id"},
+		{"User.userId", "UserID", "user_id"},
+		{"User.user_id", "UserID", "user_id"},
+	} {
+		field, err := ParseExcludedField(tt.spec)
+		if err != nil {
+			t.Fatalf("excluded field parsing failed; %s", err)
+		}
+		if !field.Matches("User", tt.field, tt.column) {
+			t.Errorf("expected %s to match the %s field", tt.spec, tt.field)
+		}
+	}
+
+	for _, spec := range []string{"createdAt", "User.", "public.User.createdAt"} {
+		if _, err := ParseExcludedField(spec); err == nil {
+			t.Errorf("expected invalid field specifier %q to fail", spec)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package opts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// InheritFrom points to the codegen block of the sqlc config which settings are mirrored by the fixture plugin,
+// e.g. `{config: sqlc.yaml, plugin: golang}`.
+type InheritFrom struct {
+	// Config is the path of the sqlc config relative to the directory sqlc runs in.
+	Config string `json:"config" yaml:"config"`
+	// Plugin is the name of the plugin of the codegen block, usually `golang`.
+	Plugin string `json:"plugin" yaml:"plugin"`
+}
+
+// inheritedKeys are the options that should be the same in the golang and the fixture plugins.
+var inheritedKeys = []string{
+	"overrides",
+	"rename",
+	"sql_package",
+	"default_schema",
+	"initialisms",
+	"emit_exact_table_names",
+	"inflection_exclude_table_names",
+	"emit_pointers_for_null_types",
+	"exclude",
+}
+
+type sqlcConfig struct {
+	SQL []struct {
+		Codegen []sqlcCodegen `yaml:"codegen"`
+	} `yaml:"sql"`
+}
+
+type sqlcCodegen struct {
+	Plugin  string                 `yaml:"plugin"`
+	Out     string                 `yaml:"out"`
+	Options map[string]interface{} `yaml:"options"`
+}
+
+// inheritOptions adds the inherited options of the codegen block to the plugin options
+// and returns them with the package of the models generated by the codegen block.
+// The options set in the plugin options win.
+func inheritOptions(pluginOptions []byte, inherit InheritFrom, current *plugin.Codegen) ([]byte, string, error) {
+	if inherit.Config == "" || inherit.Plugin == "" {
+		return nil, "", fmt.Errorf("both config and plugin should be set")
+	}
+	// The path tried is named in the errors, because it is relative to the directory sqlc runs in.
+	path := inherit.Config
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf(
+			"sqlc config %s cannot be read; the config is read from the disk, "+
+				"so the plugin should run as a process, not as a wasm module: %w",
+			path,
+			err,
+		)
+	}
+	var config sqlcConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, "", fmt.Errorf("unmarshalling sqlc config %s: %w", path, err)
+	}
+	codegen, err := findCodegen(config, inherit.Plugin, current)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w in %s", err, path)
+	}
+
+	options := map[string]json.RawMessage{}
+	if err := json.Unmarshal(pluginOptions, &options); err != nil {
+		return nil, "", fmt.Errorf("unmarshalling plugin options: %w", err)
+	}
+	for _, key := range inheritedKeys {
+		value, ok := codegen.Options[key]
+		if !ok {
+			continue
+		}
+		if _, ok := options[key]; ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshalling option %s of the plugin %q: %w", key, inherit.Plugin, err)
+		}
+		options[key] = raw
+	}
+	merged, err := json.Marshal(options)
+	if err != nil {
+		return nil, "", err
+	}
+	return merged, codegen.packageName(), nil
+}
+
+// packageName returns the package of the generated code that is the package option
+// or the name of the output directory like in the golang plugin.
+func (c *sqlcCodegen) packageName() string {
+	if name, ok := c.Options["package"].(string); ok && name != "" {
+		return name
+	}
+	name := filepath.Base(filepath.Clean(c.Out))
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
+
+// findCodegen returns the codegen block of the plugin.
+// If several sql blocks have it, the one generating the current fixtures is chosen,
+// and without such a block the choice is ambiguous.
+func findCodegen(config sqlcConfig, pluginName string, current *plugin.Codegen) (*sqlcCodegen, error) {
+	found := make([]*sqlcCodegen, 0, 1)
+	for _, sql := range config.SQL {
+		var target *sqlcCodegen
+		isCurrent := false
+		for i, codegen := range sql.Codegen {
+			if codegen.Plugin == pluginName && target == nil {
+				target = &sql.Codegen[i]
+			}
+			if current != nil && codegen.Plugin == current.GetPlugin() && codegen.Out == current.GetOut() {
+				isCurrent = true
+			}
+		}
+		if target == nil {
+			continue
+		}
+		if isCurrent {
+			return target, nil
+		}
+		found = append(found, target)
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("codegen block of the plugin %q is not found", pluginName)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf(
+			"codegen block of the plugin %q is ambiguous: %d sql blocks have it, but none of them generates the fixtures",
+			pluginName,
+			len(found),
+		)
+	}
+}
@@ -0,0 +1,112 @@
+package opts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+)
+
+const inheritConfig = `
+version: "2"
+sql:
+  - engine: "postgresql"
+    queries: "other"
+    codegen:
+      - plugin: golang
+        out: "other"
+        options:
+          sql_package: "pgx/v4"
+  - engine: "postgresql"
+    queries: "queries"
+    codegen:
+      - plugin: fixture
+        out: "fixture"
+      - plugin: golang
+        out: "./"
+        options:
+          package: "test"
+          sql_package: "pgx/v5"
+          default_schema: "test"
+          rename:
+            url: "URL"
+          exclude:
+            - "User.createdAt"
+          overrides:
+            - db_type: "uuid"
+              go_type:
+                import: "github.com/gofrs/uuid"
+                type: "UUID"
+`
+
+func TestInheritFrom(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "sqlc.yaml")
+	if err := os.WriteFile(config, []byte(inheritConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	options, err := Parse(
+		&plugin.GenerateRequest{
+			Settings: &plugin.Settings{
+				Engine:  "postgresql",
+				Codegen: &plugin.Codegen{Plugin: "fixture", Out: "fixture"},
+			},
+			PluginOptions: []byte(`{
+				"package": "fixture",
+				"default_schema": "public",
+				"inherit_from": {"config": "` + filepath.ToSlash(config) + `", "plugin": "golang"}
+			}`),
+		},
+	)
+	if err != nil {
+		t.Fatalf("options parsing failed; %s", err)
+	}
+	if options.Package != "fixture" || options.DefaultSchema != "public" {
+		t.Errorf("the fixture options should win, got package %q and default_schema %q", options.Package, options.DefaultSchema)
+	}
+	if options.SqlPackage != "pgx/v5" {
+		t.Errorf("expected sql_package of the current sql block, got %q", options.SqlPackage)
+	}
+	if options.Engine != SQLEnginePostgresql {
+		t.Errorf("expected the engine to be kept, got %q", options.Engine)
+	}
+	if diff := cmp.Diff(map[string]string{"url": "URL"}, options.Rename); diff != "" {
+		t.Errorf("rename mismatch;\n%s", diff)
+	}
+	if diff := cmp.Diff([]ExcludedField{{Struct: "User", Field: "createdAt"}}, options.ExcludedFields); diff != "" {
+		t.Errorf("exclude mismatch;\n%s", diff)
+	}
+	if len(options.Overrides) != 1 || options.Overrides[0].GoType.Path != "github.com/gofrs/uuid" {
+		t.Errorf("expected the uuid override to be inherited, got %+v", options.Overrides)
+	}
+
+	_, err = Parse(
+		&plugin.GenerateRequest{
+			PluginOptions: []byte(`{"package": "fixture", "inherit_from": {"config": "` + filepath.ToSlash(config) + `", "plugin": "go"}}`),
+		},
+	)
+	if err == nil {
+		t.Errorf("expected a missing codegen block to fail")
+	}
+
+	_, err = Parse(
+		&plugin.GenerateRequest{
+			PluginOptions: []byte(`{"package": "fixture", "inherit_from": {"config": "` + filepath.ToSlash(config) + `", "plugin": "golang"}}`),
+		},
+	)
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") || !strings.Contains(err.Error(), config) {
+		t.Errorf("expected the codegen block without the current sql block to be ambiguous in %s, got %v", config, err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	_, err = Parse(
+		&plugin.GenerateRequest{
+			PluginOptions: []byte(`{"package": "fixture", "inherit_from": {"config": "` + filepath.ToSlash(missing) + `", "plugin": "golang"}}`),
+		},
+	)
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("expected a missing config to fail naming %s, got %v", missing, err)
+	}
+}
@@ -27,10 +27,38 @@ type Options struct {
 	SqlPackage                  string             `json:"sql_package" yaml:"sql_package"`
 	EmitPointersForNullTypes    bool               `json:"emit_pointers_for_null_types" yaml:"emit_pointers_for_null_types"`
 	PrimaryKeysColumns          []string           `json:"primary_keys_columns" yaml:"primary_keys_columns"`
+	NaturalKeysColumns          []string           `json:"natural_keys_columns" yaml:"natural_keys_columns"`
+	UniqueColumns               []string           `json:"unique_columns" yaml:"unique_columns"`
 	ModelImport                 string             `json:"model_import" yaml:"model_import"`
 	DefaultTypeValues           []DefaultTypeValue `json:"default_type_values" yaml:"default_type_values"`
-
+	SqliteDisableReturning      bool               `json:"sqlite_disable_returning" yaml:"sqlite_disable_returning"`
+	EmitFakeData                bool               `json:"emit_fake_data" yaml:"emit_fake_data"`
+	Relations                   []string           `json:"relations" yaml:"relations"`
+	Cleanup                     []TableCleanup     `json:"cleanup" yaml:"cleanup"`
+	DbGeneratedColumns          []string           `json:"db_generated_columns" yaml:"db_generated_columns"`
+	Exclude                     []string           `json:"exclude" yaml:"exclude"`
+	Views                       []string           `json:"views" yaml:"views"`
+	MaterializedViews           []string           `json:"materialized_views" yaml:"materialized_views"`
+	CompositeTypes              []string           `json:"composite_types" yaml:"composite_types"`
+	InheritFrom                 *InheritFrom       `json:"inherit_from" yaml:"inherit_from"`
+
+	Engine         SQLEngine           `json:"-" yaml:"-"`
 	InitialismsMap map[string]struct{} `json:"-" yaml:"-"`
+	PrimaryKeys    []ColumnSet         `json:"-" yaml:"-"`
+	NaturalKeys    []ColumnSet         `json:"-" yaml:"-"`
+	UniqueKeys     []ColumnSet         `json:"-" yaml:"-"`
+	ForeignKeys    []Relation          `json:"-" yaml:"-"`
+	Generated      []ColumnSet         `json:"-" yaml:"-"`
+	ExcludedFields []ExcludedField     `json:"-" yaml:"-"`
+	// ViewTables are the views followed by the materialized views of the options.
+	ViewTables []View `json:"-" yaml:"-"`
+	// Composites are the PostgreSQL composite types of the options.
+	Composites []CompositeType `json:"-" yaml:"-"`
+	// ModelPackage is the package of the models generated by the plugin of inherit_from.
+	ModelPackage string `json:"-" yaml:"-"`
+
+	// keys are the names of the options set in the config, checked by ValidateOpts.
+	keys []string
 }
 
 type GlobalOptions struct {
@@ -61,12 +89,31 @@ func Parse(req *plugin.GenerateRequest) (*Options, error) {
 
 func parseOpts(req *plugin.GenerateRequest) (*Options, error) {
 	var options Options
+	options.Engine = SQLEngine(req.GetSettings().GetEngine())
 	if len(req.PluginOptions) == 0 {
 		return &options, nil
 	}
 	if err := json.Unmarshal(req.PluginOptions, &options); err != nil {
 		return nil, fmt.Errorf("unmarshalling plugin options: %w", err)
 	}
+	pluginOptions := req.PluginOptions
+	if options.InheritFrom != nil {
+		var modelPackage string
+		var err error
+		pluginOptions, modelPackage, err = inheritOptions(req.PluginOptions, *options.InheritFrom, req.GetSettings().GetCodegen())
+		if err != nil {
+			return nil, fmt.Errorf("invalid inherit_from: %w", err)
+		}
+		options = Options{Engine: options.Engine, ModelPackage: modelPackage}
+		if err := json.Unmarshal(pluginOptions, &options); err != nil {
+			return nil, fmt.Errorf("unmarshalling inherited plugin options: %w", err)
+		}
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(pluginOptions, &object); err != nil {
+		return nil, fmt.Errorf("unmarshalling plugin options: %w", err)
+	}
+	options.keys = sortedKeys(object)
 
 	if options.Package == "" {
 		if options.Out != "" {
@@ -92,6 +139,63 @@ func parseOpts(req *plugin.GenerateRequest) (*Options, error) {
 		options.InitialismsMap[initial] = struct{}{}
 	}
 
+	primaryKeys, err := ParseColumnSets(options.PrimaryKeysColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid primary_keys_columns: %w", err)
+	}
+	options.PrimaryKeys = primaryKeys
+
+	naturalKeys, err := ParseColumnSets(options.NaturalKeysColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid natural_keys_columns: %w", err)
+	}
+	options.NaturalKeys = naturalKeys
+
+	uniqueKeys, err := ParseColumnSets(options.UniqueColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unique_columns: %w", err)
+	}
+	options.UniqueKeys = uniqueKeys
+
+	generated, err := ParseColumnSets(options.DbGeneratedColumns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid db_generated_columns: %w", err)
+	}
+	options.Generated = generated
+
+	excludedFields, err := ParseExcludedFields(options.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude: %w", err)
+	}
+	options.ExcludedFields = excludedFields
+
+	views, err := ParseViews(options.Views, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid views: %w", err)
+	}
+	materializedViews, err := ParseViews(options.MaterializedViews, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid materialized_views: %w", err)
+	}
+	options.ViewTables = append(views, materializedViews...)
+	composites, err := ParseCompositeTypes(options.CompositeTypes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid composite_types: %w", err)
+	}
+	options.Composites = composites
+
+	foreignKeys, err := ParseRelations(options.Relations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relations: %w", err)
+	}
+	options.ForeignKeys = foreignKeys
+
+	for i := range options.Cleanup {
+		if err := options.Cleanup[i].parse(); err != nil {
+			return nil, fmt.Errorf("invalid cleanup: %w", err)
+		}
+	}
+
 	return &options, nil
 }
 
@@ -111,11 +215,6 @@ func parseGlobalOpts(req *plugin.GenerateRequest) (*GlobalOptions, error) {
 	return &options, nil
 }
 
-func ValidateOpts(opts *Options) error {
-
-	return nil
-}
-
 func (o *Options) Driver() SQLDriver {
-	return NewSQLDriver(o.SqlPackage)
+	return NewSQLDriver(o.SqlPackage, o.Engine)
 }
@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Relation is a foreign key between two tables configured in the relations option,
+// e.g. `posts.author_id -> users.id` or `(posts.tenant_id, posts.author_id) -> (users.tenant_id, users.id)`.
+type Relation struct {
+	Columns    ColumnSet
+	References ColumnSet
+}
+
+func ParseRelation(spec string) (Relation, error) {
+	var relation Relation
+	parts := strings.Split(spec, "->")
+	if len(parts) != 2 {
+		return relation, fmt.Errorf(
+			"relation %q is not the proper format, expected '[schema.]tablename.colname -> [schema.]tablename.colname'",
+			spec,
+		)
+	}
+	columns, err := ParseColumnSet(parts[0])
+	if err != nil {
+		return relation, err
+	}
+	references, err := ParseColumnSet(parts[1])
+	if err != nil {
+		return relation, err
+	}
+	if len(columns.Columns) != len(references.Columns) {
+		return relation, fmt.Errorf("relation %q should reference the same number of columns", spec)
+	}
+	relation.Columns = columns
+	relation.References = references
+
+	return relation, nil
+}
+
+func ParseRelations(specs []string) ([]Relation, error) {
+	relations := make([]Relation, 0, len(specs))
+	for _, spec := range specs {
+		relation, err := ParseRelation(spec)
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, relation)
+	}
+	return relations, nil
+}
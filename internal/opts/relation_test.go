@@ -0,0 +1,64 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRelation(t *testing.T) {
+	for _, test := range []struct {
+		spec     string
+		relation Relation
+	}{
+		{
+			"posts.author_id -> users.id",
+			Relation{
+				Columns:    ColumnSet{Table: "posts", Columns: []string{"author_id"}},
+				References: ColumnSet{Table: "users", Columns: []string{"id"}},
+			},
+		},
+		{
+			"(blog.posts.tenant_id, blog.posts.author_id)->(auth.users.tenant_id, auth.users.id)",
+			Relation{
+				Columns:    ColumnSet{Schema: "blog", Table: "posts", Columns: []string{"tenant_id", "author_id"}},
+				References: ColumnSet{Schema: "auth", Table: "users", Columns: []string{"tenant_id", "id"}},
+			},
+		},
+	} {
+		tt := test
+		t.Run(tt.spec, func(t *testing.T) {
+			relation, err := ParseRelation(tt.spec)
+			if err != nil {
+				t.Fatalf("relation parsing failed; %s", err)
+			}
+			if diff := cmp.Diff(tt.relation, relation); diff != "" {
+				t.Errorf("relation mismatch;\n%s", diff)
+			}
+		})
+	}
+	for _, test := range []struct {
+		spec string
+		err  string
+	}{
+		{
+			"posts.author_id",
+			"relation \"posts.author_id\" is not the proper format, expected '[schema.]tablename.colname -> [schema.]tablename.colname'",
+		},
+		{
+			"(posts.tenant_id, posts.author_id) -> users.id",
+			"relation \"(posts.tenant_id, posts.author_id) -> users.id\" should reference the same number of columns",
+		},
+	} {
+		tt := test
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := ParseRelation(tt.spec)
+			if err == nil {
+				t.Fatalf("expected parse to fail; got nil")
+			}
+			if diff := cmp.Diff(tt.err, err.Error()); diff != "" {
+				t.Errorf("error mismatch;\n%s", diff)
+			}
+		})
+	}
+}
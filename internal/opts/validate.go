@@ -0,0 +1,242 @@
+package opts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+)
+
+// ValidateOpts checks the options against each other and against the tables of the catalog,
+// so a misconfiguration is reported before the code is generated. All the found problems are returned.
+func ValidateOpts(opts *Options, catalog *plugin.Catalog) error {
+	var errs []error
+	for _, key := range opts.keys {
+		if err := validateKey(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.SqlPackage != "" {
+		if err := validatePackage(opts.SqlPackage); err != nil {
+			errs = append(errs, fmt.Errorf("invalid sql_package: %w", err))
+		} else if err := validateEngine(opts.SqlPackage, opts.Engine); err != nil {
+			errs = append(errs, fmt.Errorf("invalid sql_package: %w", err))
+		}
+	}
+	// Without model_import the models are referenced without a package,
+	// but the fixtures are generated to the folder of their own package.
+	if opts.ModelImport == "" && opts.ModelPackage != "" && opts.Package != opts.ModelPackage {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"model_import is required, because the package %q differs from the package %q of the models",
+				opts.Package,
+				opts.ModelPackage,
+			),
+		)
+	} else if opts.ModelImport == "" {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"model_import is required, because the fixtures are generated to the folder of the package %q apart from the models",
+				opts.Package,
+			),
+		)
+	}
+	if len(opts.MaterializedViews) > 0 && opts.Engine != SQLEnginePostgresql {
+		errs = append(errs, fmt.Errorf("invalid materialized_views: materialized views are supported only by PostgreSQL"))
+	}
+	if len(opts.CompositeTypes) > 0 && opts.Engine != SQLEnginePostgresql {
+		errs = append(errs, fmt.Errorf("invalid composite_types: composite types are supported only by PostgreSQL"))
+	}
+	for _, cleanup := range opts.Cleanup {
+		if cleanup.Strategy == CleanupTruncateCascade && opts.Engine == SQLEngineMySQL {
+			errs = append(
+				errs,
+				fmt.Errorf(
+					"invalid cleanup of the table %q: MySQL cannot truncate the tables referenced by foreign keys, use the delete or delete_where strategy",
+					cleanup.Table,
+				),
+			)
+		}
+	}
+	if catalog != nil {
+		errs = append(errs, validateColumnSets("primary_keys_columns", opts.PrimaryKeys, catalog)...)
+		errs = append(errs, validateColumnSets("natural_keys_columns", opts.NaturalKeys, catalog)...)
+		errs = append(errs, validateColumnSets("unique_columns", opts.UniqueKeys, catalog)...)
+		errs = append(errs, validateColumnSets("db_generated_columns", opts.Generated, catalog)...)
+		errs = append(errs, validateViews("views", opts.Views, catalog)...)
+		errs = append(errs, validateViews("materialized_views", opts.MaterializedViews, catalog)...)
+		errs = append(errs, validateCompositeTypes(opts.Composites, catalog)...)
+		errs = append(errs, validateCleanups(opts.Cleanup, catalog)...)
+		for _, relation := range opts.ForeignKeys {
+			sets := []ColumnSet{relation.Columns, relation.References}
+			errs = append(errs, validateColumnSets("relations", sets, catalog)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateKey checks that the key is a known option and suggests the closest known option if it is not.
+func validateKey(key string) error {
+	known := knownKeys()
+	if _, ok := known[key]; ok {
+		return nil
+	}
+	suggestion := ""
+	bestDistance := len(key)/3 + 1
+	for option := range known {
+		if distance := levenshtein(key, option); distance < bestDistance || distance == bestDistance && option < suggestion {
+			suggestion = option
+			bestDistance = distance
+		}
+	}
+	if suggestion == "" {
+		return fmt.Errorf("unknown option %q", key)
+	}
+	return fmt.Errorf("unknown option %q, did you mean %s?", key, suggestion)
+}
+
+// knownKeys returns the JSON names of the options.
+func knownKeys() map[string]struct{} {
+	keys := map[string]struct{}{}
+	t := reflect.TypeOf(Options{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// validateColumnSets checks that the tables and the columns of the sets exist in the catalog.
+func validateColumnSets(option string, sets []ColumnSet, catalog *plugin.Catalog) []error {
+	var errs []error
+	for _, set := range sets {
+		tables := findTables(catalog, set)
+		if len(tables) == 0 {
+			errs = append(errs, fmt.Errorf("invalid %s: table %q is not found", option, set.name()))
+			continue
+		}
+		for _, column := range set.Columns {
+			for _, table := range tables {
+				if !hasColumn(table, column) {
+					errs = append(
+						errs,
+						fmt.Errorf("invalid %s: column %q is not found in table %q", option, column, set.name()),
+					)
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateViews checks that the configured views exist in the catalog.
+func validateViews(option string, specs []string, catalog *plugin.Catalog) []error {
+	var errs []error
+	for _, spec := range specs {
+		view, err := ParseView(spec, false)
+		if err != nil {
+			continue
+		}
+		if len(findTables(catalog, ColumnSet{Schema: view.Schema, Table: view.Name})) == 0 {
+			errs = append(errs, fmt.Errorf("invalid %s: view %q is not found", option, spec))
+		}
+	}
+	return errs
+}
+
+// validateCompositeTypes checks that the configured composite types exist in the catalog.
+func validateCompositeTypes(types []CompositeType, catalog *plugin.Catalog) []error {
+	var errs []error
+	for _, compositeType := range types {
+		found := false
+		for _, schema := range catalog.Schemas {
+			if compositeType.Schema != "" && compositeType.Schema != schema.Name {
+				continue
+			}
+			for _, catalogType := range schema.CompositeTypes {
+				if catalogType.GetName() == compositeType.Name {
+					found = true
+				}
+			}
+		}
+		if !found {
+			errs = append(
+				errs,
+				fmt.Errorf("invalid composite_types: composite type %q is not found", compositeType.qualifiedName()),
+			)
+		}
+	}
+	return errs
+}
+
+// validateCleanups checks that the tables of the cleanup strategies exist in the catalog.
+func validateCleanups(cleanups []TableCleanup, catalog *plugin.Catalog) []error {
+	var errs []error
+	for _, cleanup := range cleanups {
+		if len(findTables(catalog, ColumnSet{Schema: cleanup.schema, Table: cleanup.tableName})) == 0 {
+			errs = append(errs, fmt.Errorf("invalid cleanup: table %q is not found", cleanup.Table))
+		}
+	}
+	return errs
+}
+
+func findTables(catalog *plugin.Catalog, set ColumnSet) []*plugin.Table {
+	var tables []*plugin.Table
+	for _, schema := range catalog.Schemas {
+		for _, table := range schema.Tables {
+			if set.Matches(schema.Name, table.Rel.GetName()) {
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables
+}
+
+func hasColumn(table *plugin.Table, name string) bool {
+	for _, column := range table.Columns {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the number of the single character edits turning a into b.
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// sortedKeys returns the keys of the JSON object in the alphabetical order.
+func sortedKeys(object map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
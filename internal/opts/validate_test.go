@@ -0,0 +1,118 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+)
+
+func TestValidateOpts(t *testing.T) {
+	catalog := &plugin.Catalog{
+		DefaultSchema: "public",
+		Schemas: []*plugin.Schema{
+			{
+				Name: "public",
+				Tables: []*plugin.Table{
+					{
+						Rel:     &plugin.Identifier{Schema: "public", Name: "memberships"},
+						Columns: []*plugin.Column{{Name: "tenant_id"}, {Name: "user_id"}},
+					},
+				},
+				CompositeTypes: []*plugin.CompositeType{{Name: "address"}},
+			},
+		},
+	}
+	parse := func(t *testing.T, pluginOptions string) *Options {
+		options, err := Parse(
+			&plugin.GenerateRequest{
+				Settings:      &plugin.Settings{Engine: "postgresql"},
+				PluginOptions: []byte(pluginOptions),
+			},
+		)
+		if err != nil {
+			t.Fatalf("options parsing failed; %s", err)
+		}
+		return options
+	}
+
+	options := parse(t, `{"package": "fixture", "model_import": "example.com/app/models", "sql_package": "pgx/v5", "primary_keys_columns": ["(memberships.tenant_id, memberships.user_id)"], "composite_types": ["public.address(street text)"]}`)
+	if err := ValidateOpts(options, catalog); err != nil {
+		t.Errorf("expected valid options, got %s", err)
+	}
+
+	for _, test := range []struct {
+		options string
+		err     string
+	}{
+		{
+			`{"package": "fixture", "primary_key_columns": ["memberships.user_id"]}`,
+			`unknown option "primary_key_columns", did you mean primary_keys_columns?`,
+		},
+		{
+			`{"package": "fixture", "foo": true}`,
+			`unknown option "foo"`,
+		},
+		{
+			`{"package": "fixture", "sql_package": "pgx/v6"}`,
+			`invalid sql_package: unknown SQL package: pgx/v6`,
+		},
+		{
+			`{"package": "fixture", "primary_keys_columns": ["members.user_id"]}`,
+			`invalid primary_keys_columns: table "members" is not found`,
+		},
+		{
+			`{"package": "fixture", "primary_keys_columns": ["public.memberships.role"]}`,
+			`invalid primary_keys_columns: column "role" is not found in table "public.memberships"`,
+		},
+		{
+			`{"package": "fixture", "composite_types": ["money_range(\"from\" bigint, \"to\" bigint)"]}`,
+			`invalid composite_types: composite type "money_range" is not found`,
+		},
+		{
+			`{"package": "fixture", "relations": ["memberships.user_id -> users.id"]}`,
+			`invalid relations: table "users" is not found`,
+		},
+		{
+			`{"package": "fixture", "cleanup": [{"table": "members", "strategy": "none"}]}`,
+			`invalid cleanup: table "members" is not found`,
+		},
+		{
+			`{"package": "fixture"}`,
+			`model_import is required, because the fixtures are generated to the folder of the package "fixture" apart from the models`,
+		},
+	} {
+		tt := test
+		t.Run(tt.err, func(t *testing.T) {
+			err := ValidateOpts(parse(t, tt.options), catalog)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	options = parse(t, `{"package": "fixture", "model_import": "example.com/app/models", "cleanup": [{"table": "memberships", "strategy": "truncate_cascade"}]}`)
+	if err := ValidateOpts(options, catalog); err != nil {
+		t.Errorf("expected valid options, got %s", err)
+	}
+	options.Engine = SQLEngineMySQL
+	if err := ValidateOpts(options, catalog); err == nil || !strings.Contains(err.Error(), "MySQL cannot truncate") {
+		t.Errorf("expected truncate_cascade on MySQL to fail, got %v", err)
+	}
+
+	options = parse(t, `{"package": "fixture", "model_import": "example.com/app/models", "sql_package": "pgx/v5"}`)
+	options.Engine = SQLEngineSQLite
+	if err := ValidateOpts(options, catalog); err == nil || !strings.Contains(err.Error(), "SQL package pgx/v5 supports only the postgresql engine") {
+		t.Errorf("expected pgx/v5 with SQLite to fail, got %v", err)
+	}
+
+	options = parse(t, `{"package": "fixture"}`)
+	options.ModelPackage = "test"
+	if err := ValidateOpts(options, catalog); err == nil || !strings.Contains(err.Error(), "model_import is required") {
+		t.Errorf("expected missing model_import to fail, got %v", err)
+	}
+	options.ModelImport = "example.com/app/test"
+	if err := ValidateOpts(options, catalog); err != nil {
+		t.Errorf("expected valid options, got %s", err)
+	}
+}
@@ -0,0 +1,63 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// View is a view or a materialized view. sqlc puts the views into the catalog as tables
+// without telling them apart, so the views are configured in the views and materialized_views options.
+type View struct {
+	Schema       string
+	Name         string
+	Materialized bool
+}
+
+// ParseView parses the view written as `viewname` or `schema.viewname`.
+func ParseView(spec string, materialized bool) (View, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ".")
+	view := View{Materialized: materialized}
+	switch len(parts) {
+	case 1:
+		view.Name = parts[0]
+	case 2:
+		view.Schema, view.Name = parts[0], parts[1]
+	default:
+		return view, fmt.Errorf("view specifier %q is not the proper format, expected '[schema.]viewname'", spec)
+	}
+	if view.Name == "" {
+		return view, fmt.Errorf("view specifier %q is not the proper format, expected '[schema.]viewname'", spec)
+	}
+	return view, nil
+}
+
+func ParseViews(specs []string, materialized bool) ([]View, error) {
+	views := make([]View, 0, len(specs))
+	for _, spec := range specs {
+		view, err := ParseView(spec, materialized)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// Matches reports whether the view is the table of the catalog.
+// A view without a schema matches the table in any schema.
+func (v View) Matches(schema, table string) bool {
+	if v.Name != table {
+		return false
+	}
+	return v.Schema == "" || v.Schema == schema
+}
+
+// FindView returns the first view matching the table of the catalog.
+func FindView(views []View, schema, table string) (View, bool) {
+	for _, view := range views {
+		if view.Matches(schema, table) {
+			return view, true
+		}
+	}
+	return View{}, false
+}
@@ -0,0 +1,33 @@
+package opts
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseViews(t *testing.T) {
+	views, err := ParseViews([]string{"user_stats", " reports.monthly_totals "}, true)
+	if err != nil {
+		t.Fatalf("views parsing failed; %s", err)
+	}
+	expected := []View{
+		{Name: "user_stats", Materialized: true},
+		{Schema: "reports", Name: "monthly_totals", Materialized: true},
+	}
+	if diff := cmp.Diff(expected, views); diff != "" {
+		t.Errorf("views mismatch;\n%s", diff)
+	}
+	if _, ok := FindView(views, "public", "user_stats"); !ok {
+		t.Errorf("expected user_stats to match a view in any schema")
+	}
+	if _, ok := FindView(views, "public", "monthly_totals"); ok {
+		t.Errorf("expected monthly_totals not to match a view in another schema")
+	}
+
+	for _, spec := range []string{"", "a.b.c", "reports."} {
+		if _, err := ParseView(spec, false); err == nil {
+			t.Errorf("expected invalid view specifier %q to fail", spec)
+		}
+	}
+}
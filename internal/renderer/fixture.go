@@ -11,17 +11,33 @@ import (
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
 	"github.com/sqlc-dev/plugin-sdk-go/sdk"
 	"go/format"
+	"regexp"
+	"strconv"
+	"strings"
 	"text/template"
 )
 
+// supportFilePrefix starts the names of the files of the code shared by the fixtures,
+// so they do not clash with the files of the fixtures named after the tables, e.g. sessions.
+const supportFilePrefix = "zz_fixtures_"
+
+var sqlLineBreaks = regexp.MustCompile(`\s*\n\s*`)
+
 type FixtureTplData struct {
-	Struct               model.Struct
-	Helper               *StructHelper
-	Package              string
-	PrimaryKeyColumnName string
-	PrimaryKeyFieldType  string
-	PrimaryKeyFieldName  string
-	Imports              []imports.Import
+	Struct  model.Struct
+	Helper  *StructHelper
+	Package string
+	Imports []imports.Import
+}
+
+type FakeDataTplData struct {
+	Package string
+}
+
+type CompositeTypesTplData struct {
+	Composites []*model.Composite
+	Package    string
+	Imports    []imports.Import
 }
 
 type FixtureFactoryTplData struct {
@@ -29,13 +45,14 @@ type FixtureFactoryTplData struct {
 	Package      string
 	Imports      []imports.Import
 	ModelPackage string
+	ExecFunc     string
 }
 
 type FixtureRenderer struct {
 	structs       []model.Struct
 	loaderPackage string
 	importer      *imports.ImportBuilder
-	driver        opts.SQLDriver
+	options       *opts.Options
 }
 
 func NewFixtureRenderer(
@@ -47,7 +64,7 @@ func NewFixtureRenderer(
 		structs:       structs,
 		loaderPackage: options.Package,
 		importer:      importer,
-		driver:        options.Driver(),
+		options:       options,
 	}
 }
 
@@ -62,6 +79,7 @@ func (r *FixtureRenderer) Render() ([]*plugin.File, error) {
 
 			return title
 		},
+		"sql": sqlLiteral,
 	}
 	tmpl := template.Must(
 		template.New("fixture.tmpl").
@@ -69,27 +87,116 @@ func (r *FixtureRenderer) Render() ([]*plugin.File, error) {
 			ParseFS(
 				templates,
 				"templates/fixture.tmpl",
+				"templates/fake_data.tmpl",
+				"templates/fixtures.tmpl",
+				"templates/test_context.tmpl",
+				"templates/savepoint.tmpl",
+				"templates/session.tmpl",
+				"templates/view.tmpl",
+				"templates/composite_types.tmpl",
 			),
 	)
 	files := make([]*plugin.File, 0)
 	loaderImporter := r.importer.
 		AddWithoutAlias("testing").
 		AddWithoutAlias("context")
+	viewImporter := r.importer.AddWithoutAlias("testing")
 
 	for _, s := range r.structs {
-		if !s.HasPrimaryKey() {
-			continue
+		importer := loaderImporter
+		if s.IsView() {
+			importer = viewImporter
+		}
+		file, err := r.renderFixture(tmpl, s, importer)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	file, err := r.renderFixtures(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	file, err = r.renderSupport(
+		tmpl,
+		"test_context",
+		r.importer.AddWithoutAlias("context").AddWithoutAlias("testing"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	file, err = r.renderSupport(
+		tmpl,
+		"savepoint",
+		r.importer.
+			AddWithoutAlias("context").
+			AddWithoutAlias("strconv").
+			AddWithoutAlias("sync/atomic").
+			AddWithoutAlias("testing").
+			Add(r.structs[0].Type().Import()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	file, err = r.renderSupport(
+		tmpl,
+		"session",
+		r.importer.
+			AddWithoutAlias("context").
+			AddWithoutAlias("errors").
+			AddWithoutAlias("fmt").
+			AddWithoutAlias("sort").
+			AddWithoutAlias("sync").
+			AddWithoutAlias("testing"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	if composites := r.composites(); len(composites) > 0 {
+		file, err := r.renderCompositeTypes(tmpl, composites)
+		if err != nil {
+			return nil, err
 		}
-		file, err := r.renderFixture(tmpl, s, loaderImporter)
+		files = append(files, file)
+	}
+
+	if r.options.EmitFakeData {
+		file, err := r.renderFakeData(tmpl)
 		if err != nil {
 			return nil, err
 		}
 		files = append(files, file)
 	}
 
+	seen := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		if _, ok := seen[file.Name]; ok {
+			return nil, fmt.Errorf("the file %s is generated twice, rename the table with the rename option", file.Name)
+		}
+		seen[file.Name] = struct{}{}
+	}
+
 	return files, nil
 }
 
+// sqlLiteral formats an SQL statement as a Go string literal.
+// A raw string literal is used unless the statement contains backticks, as MySQL identifiers do.
+func sqlLiteral(query string) string {
+	if !strings.Contains(query, "`") {
+		return "`" + query + "`"
+	}
+	return strconv.Quote(strings.TrimSpace(sqlLineBreaks.ReplaceAllString(query, " ")))
+}
+
 func (r *FixtureRenderer) renameReservedWords(title string) string {
 	if title == "type" {
 		return "typ"
@@ -150,29 +257,27 @@ func (r *FixtureRenderer) renderFixture(
 	s model.Struct,
 	importer *imports.ImportBuilder,
 ) (*plugin.File, error) {
-	var pkField model.Field
-	for _, f := range s.Fields() {
-		if f.IsPrimaryKey() {
-			pkField = f
-			break
-		}
+	helper := NewStructHelper(s, r.options)
+	// The fixture of a view uses only the types of the key fields, so the helper returns all its imports.
+	if !s.IsView() {
+		importer = importer.ImportContainer(&s)
 	}
-
 	tctx := FixtureTplData{
-		Struct:               s,
-		Helper:               NewStructHelper(s, r.driver),
-		Package:              r.loaderPackage,
-		PrimaryKeyColumnName: pkField.DBName(),
-		PrimaryKeyFieldType:  pkField.Type().TypeWithPackage(),
-		PrimaryKeyFieldName:  pkField.Name(),
+		Struct:  s,
+		Helper:  helper,
+		Package: r.loaderPackage,
 		Imports: importer.
-			ImportContainer(&s).
+			ImportContainer(helper).
 			Build(),
 	}
 
+	name := "fixture.tmpl"
+	if s.IsView() {
+		name = "view.tmpl"
+	}
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
-	err := tmpl.ExecuteTemplate(w, "fixture.tmpl", &tctx)
+	err := tmpl.ExecuteTemplate(w, name, &tctx)
 	w.Flush()
 	if err != nil {
 		fmt.Println(b.String())
@@ -180,17 +285,157 @@ func (r *FixtureRenderer) renderFixture(
 	}
 	code, err := format.Source(b.Bytes())
 	if err != nil {
-		//fmt.Println(b.String())
-		//return nil, fmt.Errorf("source error1: %w", err)
-		code = b.Bytes()
-	}
-	filename := fmt.Sprintf("%s_loader.go", strcase.ToSnake(s.Type().TypeName()))
-	if r.loaderPackage != s.Type().PackageName() {
-		filename = fmt.Sprintf("%s/%s.go", r.loaderPackage, strcase.ToSnake(s.Type().TypeName()))
+		return nil, fmt.Errorf("formatting the fixture of %s: %w", s.FullTableName(), err)
 	}
 	file := &plugin.File{
-		Name:     filename,
+		Name:     r.fileName(strcase.ToSnake(s.Type().TypeName()), s.Type().PackageName()),
 		Contents: code,
 	}
 	return file, nil
 }
+
+// renderFixtures renders the Fixtures struct holding the default fixtures of all the tables.
+func (r *FixtureRenderer) renderFixtures(tmpl *template.Template) (*plugin.File, error) {
+	modelType := r.structs[0].Type()
+	tctx := FixtureFactoryTplData{
+		Structs: r.structs,
+		Package: r.loaderPackage,
+		Imports: r.importer.
+			Add(modelType.Import()).
+			Build(),
+		ModelPackage: modelType.PackageName(),
+		ExecFunc:     NewStructHelper(r.structs[0], r.options).ExecFunc(),
+	}
+
+	var b bytes.Buffer
+	err := tmpl.ExecuteTemplate(&b, "fixtures.tmpl", &tctx)
+	if err != nil {
+		return nil, err
+	}
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting fixtures: %w", err)
+	}
+	return &plugin.File{
+		Name:     r.fileName("zz_fixtures", modelType.PackageName()),
+		Contents: code,
+	}, nil
+}
+
+// renderSupport renders the template of the code shared by the fixtures, e.g. test_context.tmpl,
+// to the file of the same name with the prefix of the support files.
+func (r *FixtureRenderer) renderSupport(
+	tmpl *template.Template,
+	name string,
+	importer *imports.ImportBuilder,
+) (*plugin.File, error) {
+	modelType := r.structs[0].Type()
+	tctx := FixtureFactoryTplData{
+		Package:      r.loaderPackage,
+		Imports:      importer.Build(),
+		ModelPackage: modelType.PackageName(),
+		ExecFunc:     NewStructHelper(r.structs[0], r.options).ExecFunc(),
+	}
+
+	var b bytes.Buffer
+	err := tmpl.ExecuteTemplate(&b, name+".tmpl", &tctx)
+	if err != nil {
+		return nil, err
+	}
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", name, err)
+	}
+	return &plugin.File{
+		Name:     r.fileName(supportFilePrefix+name, modelType.PackageName()),
+		Contents: code,
+	}, nil
+}
+
+// renderFakeData renders the functions generating the fake values used by the fixtures
+// if the emit_fake_data option is on.
+func (r *FixtureRenderer) renderFakeData(tmpl *template.Template) (*plugin.File, error) {
+	tctx := FakeDataTplData{
+		Package: r.loaderPackage,
+	}
+
+	var b bytes.Buffer
+	err := tmpl.ExecuteTemplate(&b, "fake_data.tmpl", &tctx)
+	if err != nil {
+		return nil, err
+	}
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting fake data: %w", err)
+	}
+	return &plugin.File{
+		Name:     r.fileName(supportFilePrefix+"fake_data", r.structs[0].Type().PackageName()),
+		Contents: code,
+	}, nil
+}
+
+// composites returns the structs of the composite types used by the fields of the tables, each type once.
+func (r *FixtureRenderer) composites() []*model.Composite {
+	var composites []*model.Composite
+	seen := make(map[string]struct{})
+	for _, s := range r.structs {
+		for _, field := range s.Fields() {
+			composite := field.Composite()
+			if composite == nil {
+				continue
+			}
+			if _, ok := seen[composite.Name()]; ok {
+				continue
+			}
+			seen[composite.Name()] = struct{}{}
+			composites = append(composites, composite)
+		}
+	}
+	return composites
+}
+
+// renderCompositeTypes renders the structs of the composite types accepted by the fixture setters.
+func (r *FixtureRenderer) renderCompositeTypes(
+	tmpl *template.Template,
+	composites []*model.Composite,
+) (*plugin.File, error) {
+	importer := r.importer.
+		AddWithoutAlias("fmt").
+		AddWithoutAlias("strings")
+	for _, composite := range composites {
+		for _, field := range composite.Fields() {
+			if field.Type != "string" {
+				importer = importer.AddWithoutAlias("strconv")
+			}
+		}
+	}
+	tctx := CompositeTypesTplData{
+		Composites: composites,
+		Package:    r.loaderPackage,
+		Imports:    importer.Build(),
+	}
+
+	var b bytes.Buffer
+	err := tmpl.ExecuteTemplate(&b, "composite_types.tmpl", &tctx)
+	if err != nil {
+		return nil, err
+	}
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting composite types: %w", err)
+	}
+	return &plugin.File{
+		Name:     r.fileName(supportFilePrefix+"composite_types", r.structs[0].Type().PackageName()),
+		Contents: code,
+	}, nil
+}
+
+// fileName returns the name of the generated file.
+// The file is placed in the models package with the "_loader" suffix
+// or in the subfolder named after the fixture package.
+func (r *FixtureRenderer) fileName(name string, modelPackage string) string {
+	if r.loaderPackage != modelPackage {
+		return fmt.Sprintf("%s/%s.go", r.loaderPackage, name)
+	}
+	return fmt.Sprintf("%s_loader.go", name)
+}
@@ -2,21 +2,99 @@ package renderer
 
 import (
 	"fmt"
+	"github.com/debugger84/sqlc-fixture/internal/imports"
 	"github.com/debugger84/sqlc-fixture/internal/model"
 	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"github.com/sqlc-dev/plugin-sdk-go/sdk"
+	"strconv"
 	"strings"
 )
 
+// incomparableTypes are the column types that have no equality operator,
+// so a row cannot be found by their values.
+var incomparableTypes = map[string]struct{}{
+	"json":    {},
+	"xml":     {},
+	"point":   {},
+	"line":    {},
+	"lseg":    {},
+	"box":     {},
+	"path":    {},
+	"polygon": {},
+	"circle":  {},
+}
+
+// integerTypes are the Go types of the columns that can be filled by AUTO_INCREMENT.
+var integerTypes = map[string]struct{}{
+	"int":    {},
+	"int8":   {},
+	"int16":  {},
+	"int32":  {},
+	"int64":  {},
+	"uint":   {},
+	"uint8":  {},
+	"uint16": {},
+	"uint32": {},
+	"uint64": {},
+}
+
+// nullableValueFields maps the nullable Go types to the names of the fields holding their values.
+var nullableValueFields = map[string]string{
+	"sql.NullString":     "String",
+	"sql.NullInt64":      "Int64",
+	"sql.NullInt32":      "Int32",
+	"sql.NullInt16":      "Int16",
+	"sql.NullByte":       "Byte",
+	"sql.NullFloat64":    "Float64",
+	"sql.NullBool":       "Bool",
+	"sql.NullTime":       "Time",
+	"uuid.NullUUID":      "UUID",
+	"pgtype.Text":        "String",
+	"pgtype.Int8":        "Int64",
+	"pgtype.Int4":        "Int32",
+	"pgtype.Int2":        "Int16",
+	"pgtype.Float8":      "Float64",
+	"pgtype.Float4":      "Float32",
+	"pgtype.Bool":        "Bool",
+	"pgtype.Timestamptz": "Time",
+	"pgtype.Timestamp":   "Time",
+	"pgtype.Date":        "Time",
+}
+
+// timeUnits are the Go durations of the fractional seconds precision of the MySQL time columns.
+var timeUnits = []string{
+	"time.Second",
+	"100 * time.Millisecond",
+	"10 * time.Millisecond",
+	"time.Millisecond",
+	"100 * time.Microsecond",
+	"10 * time.Microsecond",
+	"time.Microsecond",
+}
+
+// maxQueryParams are the limits of the number of parameters in one statement.
+var maxQueryParams = map[opts.SQLEngine]int{
+	opts.SQLEnginePostgresql: 65535,
+	opts.SQLEngineMySQL:      65535,
+	opts.SQLEngineSQLite:     32766,
+}
+
+// maxDeleteBatchSize limits the number of the conditions joined by OR in one DELETE statement,
+// because SQLite limits the depth of an expression tree by 1000.
+const maxDeleteBatchSize = 500
+
 type StructHelper struct {
-	s      model.Struct
-	driver opts.SQLDriver
+	s                model.Struct
+	driver           opts.SQLDriver
+	engine           opts.SQLEngine
+	disableReturning bool
 }
 
 func (h *StructHelper) ColumnNames() string {
 	fields := h.s.Fields()
 	names := make([]string, len(fields))
 	for i, field := range fields {
-		names[i] = fmt.Sprintf("\"%s\"", field.DBName())
+		names[i] = h.quote(field.DBName())
 	}
 
 	return strings.Join(names, ", ")
@@ -28,54 +106,737 @@ func (h *StructHelper) ColumnPlaceholders() string {
 		if i > 0 {
 			out += ", "
 		}
-		if h.driver.IsPGX() || h.driver.IsLibPQ() {
-			out += fmt.Sprintf("$%d", i+1)
-		} else {
-			out += "?"
-		}
+		out += h.placeholder(i + 1)
+	}
+	return out
+}
+
+// InsertSql returns the INSERT statement of all the fields.
+// The inserted row is returned back if the database supports it.
+func (h *StructHelper) InsertSql() string {
+	out := fmt.Sprintf(
+		"INSERT INTO %s\n            (%s)\n            VALUES (%s)",
+		h.TableName(),
+		h.ColumnNames(),
+		h.ColumnPlaceholders(),
+	)
+	if h.HasReturning() {
+		out += fmt.Sprintf("\n            RETURNING %s", h.ColumnNames())
+	}
+	return out + "\n        "
+}
+
+// InsertIntoSql returns the beginning of the INSERT statement
+// that is followed by the list of the columns having values at runtime.
+func (h *StructHelper) InsertIntoSql() string {
+	return fmt.Sprintf("INSERT INTO %s", h.TableName())
+}
+
+// DefaultValuesInsertSql returns the INSERT statement of a row that has all the columns filled by the database.
+func (h *StructHelper) DefaultValuesInsertSql() string {
+	if h.engine == opts.SQLEngineMySQL {
+		return fmt.Sprintf("INSERT INTO %s () VALUES ()", h.TableName())
+	}
+	out := fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", h.TableName())
+	if h.HasReturning() {
+		out += " " + h.ReturningSql()
 	}
 	return out
 }
 
+// ColumnLiteral returns the Go string literal of the quoted column name of the field.
+func (h *StructHelper) ColumnLiteral(field model.Field) string {
+	return sqlLiteral(h.quote(field.DBName()))
+}
+
+// AllFieldsGenerated reports whether all the fields are generated by the database,
+// so the INSERT statement can have no columns.
+func (h *StructHelper) AllFieldsGenerated() bool {
+	for _, field := range h.s.Fields() {
+		if !field.IsGenerated() {
+			return false
+		}
+	}
+	return true
+}
+
+// PlaceholderExpr returns the Go expression of one placeholder.
+// On PostgreSQL the placeholder is numbered after the value of the offset expression.
+func (h *StructHelper) PlaceholderExpr(offset string) string {
+	return h.formatExpr(h.placeholderFormat(), offset, 1)
+}
+
+// InsertManySql returns the beginning of the INSERT statement of several rows
+// that is followed by the rows placeholders built by RowPlaceholdersExpr.
+func (h *StructHelper) InsertManySql() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES", h.TableName(), h.ColumnNames())
+}
+
+// ReturningSql returns the RETURNING clause of all the fields.
+func (h *StructHelper) ReturningSql() string {
+	return fmt.Sprintf("RETURNING %s", h.ColumnNames())
+}
+
+// RowPlaceholdersExpr returns the Go expression building the placeholders of one inserted row.
+// On PostgreSQL the placeholders are numbered starting after the value of the offset expression.
+func (h *StructHelper) RowPlaceholdersExpr(offset string) string {
+	placeholders := make([]string, len(h.s.Fields()))
+	for i := range placeholders {
+		placeholders[i] = h.placeholderFormat()
+	}
+	return h.formatExpr("("+strings.Join(placeholders, ", ")+")", offset, len(placeholders))
+}
+
+// HasMultiRowInsert reports whether several rows are inserted by one INSERT statement
+// and read back by RETURNING on PostgreSQL or by the primary key on MySQL.
+// The pgx drivers send a batch of statements instead of it.
+// SQLite does not guarantee the order of the rows returned by a multi-row INSERT,
+// so the returned rows could not be matched with the fixtures, and they are inserted one by one.
+// The rows of a table with the generated columns can have different lists of the inserted columns,
+// so they are inserted one by one too.
+func (h *StructHelper) HasMultiRowInsert() bool {
+	if h.driver.IsPGX() || h.s.HasGeneratedFields() {
+		return false
+	}
+	return h.engine == opts.SQLEnginePostgresql || h.engine == opts.SQLEngineMySQL
+}
+
+// HasBatch reports whether several rows are inserted by sending a batch of statements.
+func (h *StructHelper) HasBatch() bool {
+	return h.driver.IsPGX()
+}
+
+// InsertBatchSize returns the number of rows inserted by one statement
+// without exceeding the limit of the statement parameters.
+func (h *StructHelper) InsertBatchSize() int {
+	return h.batchSize(len(h.s.Fields()))
+}
+
+// SelectSql returns the SELECT statement of all the fields by the primary key.
+func (h *StructHelper) SelectSql() string {
+	return fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s",
+		h.ColumnNames(),
+		h.TableName(),
+		h.PrimaryKeyCondition(0),
+	)
+}
+
+// ExistsSql returns the SELECT statement checking if the row with the primary key exists.
+func (h *StructHelper) ExistsSql() string {
+	return fmt.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM %s WHERE %s)",
+		h.TableName(),
+		h.PrimaryKeyCondition(0),
+	)
+}
+
+// SelectByUniqueKeySql returns the SELECT statement of all the fields by the columns of the unique key.
+func (h *StructHelper) SelectByUniqueKeySql(key model.UniqueKey) string {
+	fields := key.Fields()
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		conditions[i] = h.columnCondition(field, h.placeholder(i+1))
+	}
+	return fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s",
+		h.ColumnNames(),
+		h.TableName(),
+		strings.Join(conditions, " AND "),
+	)
+}
+
+// NoRowsErr returns the error of the driver returned when a query selects no rows.
+func (h *StructHelper) NoRowsErr() string {
+	if h.driver.IsPGX() {
+		return "pgx.ErrNoRows"
+	}
+	return "sql.ErrNoRows"
+}
+
+// CountSql returns the SELECT statement counting the rows of the table that is followed by an optional WHERE condition.
+func (h *StructHelper) CountSql() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s", h.TableName())
+}
+
+// SelectAllSql returns the SELECT statement of all the fields of all the rows ordered by the primary key if it exists.
+func (h *StructHelper) SelectAllSql() string {
+	query := fmt.Sprintf("SELECT %s FROM %s", h.ColumnNames(), h.TableName())
+	if !h.s.HasPrimaryKey() {
+		return query
+	}
+	fields := h.s.PrimaryKeyFields()
+	columns := make([]string, len(fields))
+	for i, field := range fields {
+		columns[i] = h.quote(field.DBName())
+	}
+	return fmt.Sprintf("%s ORDER BY %s", query, strings.Join(columns, ", "))
+}
+
+// DeleteWhereSql returns the DELETE statement of the rows of the table that is followed by an optional WHERE condition.
+func (h *StructHelper) DeleteWhereSql() string {
+	return fmt.Sprintf("DELETE FROM %s", h.TableName())
+}
+
+// RefreshSql returns the statement refreshing the materialized view.
+func (h *StructHelper) RefreshSql() string {
+	return fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", h.TableName())
+}
+
+// SelectByRowIDSql returns the SELECT statement of all the fields by the SQLite rowid.
+func (h *StructHelper) SelectByRowIDSql() string {
+	return fmt.Sprintf(
+		"SELECT %s FROM %s WHERE rowid = %s",
+		h.ColumnNames(),
+		h.TableName(),
+		h.placeholder(1),
+	)
+}
+
+// DeleteSql returns the DELETE statement of the row matching the CleanupKeyFields
+// or the UPDATE statement setting the soft delete column of the row.
+func (h *StructHelper) DeleteSql() string {
+	return fmt.Sprintf("%s %s", h.DeleteManySql(), h.CleanupCondition())
+}
+
+// DeleteManySql returns the beginning of the DELETE statement of several rows
+// that is followed by the conditions built by CleanupConditionExpr joined with OR.
+func (h *StructHelper) DeleteManySql() string {
+	cleanup := h.s.Cleanup()
+	if cleanup.Strategy == opts.CleanupSoftDelete {
+		return fmt.Sprintf("UPDATE %s SET %s = CURRENT_TIMESTAMP WHERE", h.TableName(), h.quote(cleanup.Column))
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE", h.TableName())
+}
+
+// CleanupStrategy returns the strategy of deleting the rows created by the fixture.
+func (h *StructHelper) CleanupStrategy() string {
+	return string(h.s.Cleanup().Strategy)
+}
+
+// HasKeyCleanup reports whether the created rows are deleted or soft deleted one by one
+// by the values of the CleanupKeyFields.
+func (h *StructHelper) HasKeyCleanup() bool {
+	switch h.s.Cleanup().Strategy {
+	case opts.CleanupDelete, opts.CleanupSoftDelete:
+		return len(h.CleanupKeyFields()) > 0
+	}
+	return false
+}
+
+// HasTableCleanup reports whether the created rows are deleted by one statement without arguments
+// built by TableCleanupSql.
+func (h *StructHelper) HasTableCleanup() bool {
+	switch h.s.Cleanup().Strategy {
+	case opts.CleanupTruncateCascade, opts.CleanupDeleteWhere:
+		return true
+	}
+	return false
+}
+
+// TableCleanupSql returns the statement of the truncate_cascade or delete_where strategy.
+// SQLite has no TRUNCATE, so all the rows are deleted. MySQL cannot truncate the referenced tables,
+// so the truncate_cascade strategy is rejected for it by the validation of the options.
+func (h *StructHelper) TableCleanupSql() string {
+	cleanup := h.s.Cleanup()
+	if cleanup.Strategy == opts.CleanupDeleteWhere {
+		return fmt.Sprintf("DELETE FROM %s WHERE %s", h.TableName(), cleanup.Where)
+	}
+	if h.engine == opts.SQLEngineSQLite {
+		return fmt.Sprintf("DELETE FROM %s", h.TableName())
+	}
+	if cleanup.ResetSequences {
+		return fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", h.TableName())
+	}
+	return fmt.Sprintf("TRUNCATE TABLE %s CASCADE", h.TableName())
+}
+
+// ResetSequenceSql returns the statement restarting the auto-increment sequence of the primary key
+// after deleting the rows or an empty string if the sequence should not be reset.
+// TRUNCATE restarts the sequences itself, and SQLite is not supported.
+func (h *StructHelper) ResetSequenceSql() string {
+	cleanup := h.s.Cleanup()
+	if !cleanup.ResetSequences || cleanup.Strategy == opts.CleanupTruncateCascade {
+		return ""
+	}
+	if cleanup.Strategy != opts.CleanupDelete && cleanup.Strategy != opts.CleanupDeleteWhere {
+		return ""
+	}
+	field := h.AutoIncrementField()
+	if field == nil {
+		return ""
+	}
+	switch h.engine {
+	case opts.SQLEngineMySQL:
+		return fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = 1", h.TableName())
+	case opts.SQLEnginePostgresql:
+		return fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+			h.TableName(),
+			field.DBName(),
+			h.quote(field.DBName()),
+			h.TableName(),
+		)
+	}
+	return ""
+}
+
+// HasSequenceReset reports whether the fixture resets the sequence by ResetSequenceSql
+// once per test or session after deleting the created rows.
+func (h *StructHelper) HasSequenceReset() bool {
+	return (h.HasKeyCleanup() || h.HasTableCleanup()) && h.ResetSequenceSql() != ""
+}
+
+// DeleteBatchSize returns the number of rows deleted by one statement
+// without exceeding the limit of the statement parameters.
+func (h *StructHelper) DeleteBatchSize() int {
+	return min(h.batchSize(len(h.CleanupKeyFields())), maxDeleteBatchSize)
+}
+
+// UpdateSql returns the UPDATE statement of all the fields by the primary key.
+// The arguments of the statement are expected in the order of UpdateFields.
 func (h *StructHelper) UpdateSql() string {
 	out := ""
 	updatedFields := make([]string, 0, len(h.s.Fields()))
-	whereClause := ""
-	for i, field := range h.s.Fields() {
-		if field.IsPrimaryKey() {
-			whereClause = fmt.Sprintf("%s = ?", field.DBName())
-			if h.driver.IsPGX() || h.driver.IsLibPQ() {
-				whereClause = fmt.Sprintf("\"%s\" = $%d", field.DBName(), i+1)
-			}
-		} else {
-			updatedField := fmt.Sprintf("%s = ?", field.DBName())
-			if h.driver.IsPGX() || h.driver.IsLibPQ() {
-				updatedField = fmt.Sprintf("\"%s\" = $%d", field.DBName(), i+1)
-			}
-			updatedFields = append(updatedFields, updatedField)
+	position := 0
+	for _, field := range h.s.Fields() {
+		if !field.IsPrimaryKey() {
+			position++
+			updatedFields = append(updatedFields, h.columnCondition(field, h.placeholder(position)))
 		}
 	}
 	out = fmt.Sprintf(
-		"UPDATE %s SET \n            %s\n        WHERE %s",
+		"\n        UPDATE %s SET \n            %s\n        WHERE %s\n        ",
 		h.TableName(),
 		strings.Join(updatedFields, ",\n            "),
-		whereClause,
+		h.PrimaryKeyCondition(position),
 	)
 	return out
 }
 
+// UpdateFields returns the fields in the order of the UpdateSql placeholders:
+// the updated fields first and the primary key fields after them.
+func (h *StructHelper) UpdateFields() []model.Field {
+	fields := make([]model.Field, 0, len(h.s.Fields()))
+	for _, field := range h.s.Fields() {
+		if !field.IsPrimaryKey() {
+			fields = append(fields, field)
+		}
+	}
+	return append(fields, h.s.PrimaryKeyFields()...)
+}
+
+// HasUpdatableFields reports whether the struct has any fields except the primary key ones.
+func (h *StructHelper) HasUpdatableFields() bool {
+	return len(h.s.PrimaryKeyFields()) < len(h.s.Fields())
+}
+
+// PrimaryKeyCondition returns the WHERE condition matching all the primary key columns.
+// The placeholders are numbered in the order of PrimaryKeyFields starting after the offset.
+func (h *StructHelper) PrimaryKeyCondition(offset int) string {
+	fields := h.s.PrimaryKeyFields()
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		conditions[i] = h.columnCondition(field, h.placeholder(offset+i+1))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// CleanupKeyFields returns the fields that identify a row inserted by the fixture.
+// These are the primary key fields, the natural key fields for a table without
+// a primary key or, if no natural key is configured, all the comparable fields.
+func (h *StructHelper) CleanupKeyFields() []model.Field {
+	if h.s.HasPrimaryKey() {
+		return h.s.PrimaryKeyFields()
+	}
+	if fields := h.s.NaturalKeyFields(); len(fields) > 0 {
+		return fields
+	}
+	fields := make([]model.Field, 0, len(h.s.Fields()))
+	for _, field := range h.s.Fields() {
+		if _, ok := incomparableTypes[sdk.DataType(field.Column().GetType())]; ok {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// RoundedTimeFields returns the time fields rounded to the precision of their columns before insert.
+// MySQL rounds the fractional seconds of the DATETIME and TIMESTAMP values,
+// so a row of a table without a primary key could not be found by the values of the entity for cleanup.
+func (h *StructHelper) RoundedTimeFields() []model.Field {
+	if h.engine != opts.SQLEngineMySQL || h.s.HasPrimaryKey() || !h.HasKeyCleanup() {
+		return nil
+	}
+	fields := make([]model.Field, 0)
+	for _, field := range h.CleanupKeyFields() {
+		switch sdk.DataType(field.Column().GetType()) {
+		case "datetime", "timestamp":
+		default:
+			continue
+		}
+		switch field.Type().String() {
+		case "time.Time", "sql.NullTime", "*time.Time":
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// TimeRounding returns the statement rounding the time field of the entity to the precision of its column.
+// sqlc gets the length of DATETIME(n) and TIMESTAMP(n) columns as 20+n and of the columns without fractional seconds as 19.
+func (h *StructHelper) TimeRounding(entity string, field model.Field) string {
+	precision := int(field.Column().GetLength()) - 20
+	if precision < 0 {
+		precision = 0
+	}
+	if precision >= len(timeUnits) {
+		precision = len(timeUnits) - 1
+	}
+	unit := timeUnits[precision]
+	value := fmt.Sprintf("%s.%s", entity, field.Name())
+	switch field.Type().String() {
+	case "sql.NullTime":
+		return fmt.Sprintf("%s.Time = %s.Time.Round(%s)", value, value, unit)
+	case "*time.Time":
+		return fmt.Sprintf("if %s != nil {\nrounded := %s.Round(%s)\n%s = &rounded\n}", value, value, unit, value)
+	}
+	return fmt.Sprintf("%s = %s.Round(%s)", value, value, unit)
+}
+
+// CleanupCondition returns the WHERE condition matching all the CleanupKeyFields.
+// Columns of a table without a primary key can contain NULL,
+// so they are compared in the NULL-safe way.
+func (h *StructHelper) CleanupCondition() string {
+	if h.s.HasPrimaryKey() {
+		return h.PrimaryKeyCondition(0)
+	}
+	fields := h.CleanupKeyFields()
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		conditions[i] = h.nullSafeCondition(field, h.placeholder(i+1))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// CleanupConditionExpr returns the Go expression building the CleanupCondition of one row
+// to join it with the conditions of other rows.
+// On PostgreSQL the placeholders are numbered starting after the value of the offset expression.
+func (h *StructHelper) CleanupConditionExpr(offset string) string {
+	fields := h.CleanupKeyFields()
+	conditions := make([]string, len(fields))
+	for i, field := range fields {
+		if h.s.HasPrimaryKey() {
+			conditions[i] = h.columnCondition(field, h.placeholderFormat())
+		} else {
+			conditions[i] = h.nullSafeCondition(field, h.placeholderFormat())
+		}
+	}
+	return h.formatExpr("("+strings.Join(conditions, " AND ")+")", offset, len(fields))
+}
+
+// HasReturning reports whether the INSERT statement can return the inserted row.
+// SQLite supports RETURNING since 3.35, so it can be switched off for the older versions.
+func (h *StructHelper) HasReturning() bool {
+	switch h.engine {
+	case opts.SQLEngineMySQL:
+		return false
+	case opts.SQLEngineSQLite:
+		return !h.disableReturning
+	}
+	return true
+}
+
+// HasRowID reports whether an inserted row can be selected by the rowid returned from LastInsertId.
+func (h *StructHelper) HasRowID() bool {
+	return h.engine == opts.SQLEngineSQLite
+}
+
+// NeedsInsertResult reports whether the result of the INSERT statement without RETURNING
+// is used to find the inserted row.
+func (h *StructHelper) NeedsInsertResult() bool {
+	if h.s.HasPrimaryKey() {
+		return h.AutoIncrementField() != nil
+	}
+	return h.HasRowID()
+}
+
+// AutoIncrementField returns the primary key field that can be generated by the database
+// on insert and read back by LastInsertId. It is nil if the primary key is not a single integer.
+func (h *StructHelper) AutoIncrementField() *model.Field {
+	fields := h.s.PrimaryKeyFields()
+	if len(fields) != 1 {
+		return nil
+	}
+	if _, ok := integerTypes[fields[0].Type().String()]; !ok {
+		return nil
+	}
+	return &fields[0]
+}
+
+// Arg returns the expression that passes the field of the entity to a query.
+// lib/pq cannot pass slices to database/sql without wrapping them into pq.Array.
+func (h *StructHelper) Arg(entity string, field model.Field) string {
+	if h.isPQArray(field) {
+		return fmt.Sprintf("pq.Array(%s.%s)", entity, field.Name())
+	}
+	return fmt.Sprintf("%s.%s", entity, field.Name())
+}
+
+// ScanArg returns the expression that scans a column into the field of the entity.
+func (h *StructHelper) ScanArg(entity string, field model.Field) string {
+	if h.isPQArray(field) {
+		return fmt.Sprintf("pq.Array(&%s.%s)", entity, field.Name())
+	}
+	return fmt.Sprintf("&%s.%s", entity, field.Name())
+}
+
+// GetImports returns the imports required by the generated code of the helper expressions.
+func (h *StructHelper) GetImports() []imports.Import {
+	if h.s.IsView() {
+		return h.viewImports()
+	}
+	allImports := make([]imports.Import, 0)
+	for _, field := range h.s.Fields() {
+		if h.isPQArray(field) {
+			allImports = append(allImports, imports.Import{Path: "github.com/lib/pq"})
+			break
+		}
+	}
+	if h.s.HasDefaultValues() || len(h.s.RequiredRelations()) > 0 || h.s.HasGeneratedFields() {
+		allImports = append(allImports, imports.Import{Path: "reflect"})
+	}
+	if h.s.HasPrimaryKey() {
+		allImports = append(
+			allImports,
+			imports.Import{Path: "github.com/google/go-cmp/cmp"},
+			imports.Import{Path: "github.com/google/go-cmp/cmp/cmpopts"},
+			imports.Import{Path: "reflect"},
+		)
+	}
+	if len(h.s.UniqueKeys()) > 0 {
+		allImports = append(allImports, imports.Import{Path: "errors"})
+		if h.driver.IsPGX() {
+			allImports = append(allImports, imports.Import{Path: string(h.driver)})
+		} else {
+			allImports = append(allImports, imports.Import{Path: "database/sql"})
+		}
+	}
+	if h.HasBatch() {
+		allImports = append(allImports, imports.Import{Path: string(h.driver)})
+	}
+	if h.HasSequenceReset() {
+		allImports = append(allImports, imports.Import{Path: "sync"})
+	}
+	if len(h.RoundedTimeFields()) > 0 {
+		allImports = append(allImports, imports.Import{Path: "time"})
+	}
+	if h.HasMultiRowInsert() || h.HasKeyCleanup() || h.s.HasGeneratedFields() {
+		allImports = append(allImports, imports.Import{Path: "strings"})
+		if h.engine == opts.SQLEnginePostgresql {
+			allImports = append(allImports, imports.Import{Path: "fmt"})
+		}
+	}
+	return allImports
+}
+
+// viewImports returns the imports required by the generated code of the fixture of a view.
+func (h *StructHelper) viewImports() []imports.Import {
+	allImports := make([]imports.Import, 0)
+	for _, field := range h.s.Fields() {
+		if h.isPQArray(field) {
+			allImports = append(allImports, imports.Import{Path: "github.com/lib/pq"})
+			break
+		}
+	}
+	allImports = append(allImports, h.s.Type().Import())
+	for _, key := range h.s.ViewKeys() {
+		for _, field := range key.Fields() {
+			allImports = append(allImports, field.Type().Import())
+		}
+	}
+	if len(h.s.ViewKeys()) > 0 {
+		allImports = append(allImports, imports.Import{Path: "errors"})
+		if h.driver.IsPGX() {
+			allImports = append(allImports, imports.Import{Path: string(h.driver)})
+		} else {
+			allImports = append(allImports, imports.Import{Path: "database/sql"})
+		}
+	}
+	return allImports
+}
+
+// CompositeAssign returns the statement assigning the text of the composite struct value
+// to the target field of the string type that sqlc generates for the composite columns.
+func (h *StructHelper) CompositeAssign(field model.Field, target string, value string) string {
+	switch field.Type().String() {
+	case "sql.NullString":
+		return fmt.Sprintf("%s = sql.NullString{String: %s.String(), Valid: true}", target, value)
+	case "*string":
+		return fmt.Sprintf("text := %s.String()\n%s = &text", value, target)
+	}
+	return fmt.Sprintf("%s = %s.String()", target, value)
+}
+
+// CompositeDecode returns the statements decoding the text of the source field of the string type
+// that sqlc generates for the composite columns to the target value of the composite struct and returning it.
+// A NULL value leaves the target zero.
+func (h *StructHelper) CompositeDecode(field model.Field, source string, target string) string {
+	switch field.Type().String() {
+	case "sql.NullString":
+		return fmt.Sprintf(
+			"if !%[1]s.Valid {\nreturn %[2]s, nil\n}\nerr := %[2]s.UnmarshalText([]byte(%[1]s.String))\nreturn %[2]s, err",
+			source,
+			target,
+		)
+	case "*string":
+		return fmt.Sprintf(
+			"if %[1]s == nil {\nreturn %[2]s, nil\n}\nerr := %[2]s.UnmarshalText([]byte(*%[1]s))\nreturn %[2]s, err",
+			source,
+			target,
+		)
+	}
+	return fmt.Sprintf("err := %[2]s.UnmarshalText([]byte(%[1]s))\nreturn %[2]s, err", source, target)
+}
+
+// KeyValue converts the value of the parent field to the type of the foreign key field referencing it.
+// A nullable foreign key gets a valid value of its nullable type.
+func (h *StructHelper) KeyValue(field model.Field, parentField model.Field, value string) string {
+	fieldType := field.Type()
+	parentType := parentField.Type()
+	if fieldType.String() == parentType.String() {
+		return value
+	}
+	if fieldType.IsPointer() && !fieldType.IsArray() && fieldType.TypeWithPackage() == parentType.String() {
+		return fmt.Sprintf("func() %s { v := %s; return &v }()", fieldType.String(), value)
+	}
+	valueField, ok := nullableValueFields[fieldType.String()]
+	if ok && fieldType.PackageName() == "pgtype" && !strings.Contains(fieldType.Import().Path, "pgx/v5") {
+		ok = false
+	}
+	if !ok && fieldType.PackageName() == parentType.PackageName() && fieldType.TypeName() == "Null"+parentType.TypeName() {
+		valueField, ok = parentType.TypeName(), true
+	}
+	if ok {
+		return fmt.Sprintf("%s{%s: %s, Valid: true}", fieldType.String(), valueField, value)
+	}
+	return value
+}
+
+// QueryRowFunc returns the name of the DBTX method that queries a single row.
+func (h *StructHelper) QueryRowFunc() string {
+	if h.driver.IsPGX() {
+		return "QueryRow"
+	}
+	return "QueryRowContext"
+}
+
+// QueryFunc returns the name of the DBTX method that runs a query returning rows.
+func (h *StructHelper) QueryFunc() string {
+	if h.driver.IsPGX() {
+		return "Query"
+	}
+	return "QueryContext"
+}
+
+// ExecFunc returns the name of the DBTX method that executes a statement.
+func (h *StructHelper) ExecFunc() string {
+	if h.driver.IsPGX() {
+		return "Exec"
+	}
+	return "ExecContext"
+}
+
+// TableName returns the quoted name of the table used in the SQL statements.
+// On MySQL and SQLite the schema is added only if the table is not in the default schema of the catalog.
 func (h *StructHelper) TableName() string {
-	if h.driver.IsPGX() || h.driver.IsLibPQ() {
-		tn := h.s.FullTableName()
-		parts := strings.Split(tn, ".")
-		for i := range parts {
-			parts[i] = fmt.Sprintf("\"%s\"", parts[i])
+	if h.engine != opts.SQLEnginePostgresql {
+		if h.s.SchemaName() == "" {
+			return h.quote(h.s.DBName())
 		}
-		return strings.Join(parts, ".")
+		return h.quote(h.s.SchemaName()) + "." + h.quote(h.s.DBName())
+	}
+	tn := h.s.FullTableName()
+	parts := strings.Split(tn, ".")
+	for i := range parts {
+		parts[i] = h.quote(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+func (h *StructHelper) isPQArray(field model.Field) bool {
+	if h.engine != opts.SQLEnginePostgresql || !h.driver.IsLibPQ() {
+		return false
 	}
-	return h.s.FullTableName()
+	return field.Column().GetIsArray() || field.Column().GetIsSqlcSlice()
+}
+
+func (h *StructHelper) columnCondition(field model.Field, placeholder string) string {
+	return fmt.Sprintf("%s = %s", h.quote(field.DBName()), placeholder)
 }
 
-func NewStructHelper(s model.Struct, driver opts.SQLDriver) *StructHelper {
-	return &StructHelper{s: s, driver: driver}
+func (h *StructHelper) nullSafeCondition(field model.Field, placeholder string) string {
+	switch h.engine {
+	case opts.SQLEngineMySQL:
+		return fmt.Sprintf("%s <=> %s", h.quote(field.DBName()), placeholder)
+	case opts.SQLEngineSQLite:
+		return fmt.Sprintf("%s IS %s", h.quote(field.DBName()), placeholder)
+	}
+	return fmt.Sprintf("%s IS NOT DISTINCT FROM %s", h.quote(field.DBName()), placeholder)
+}
+
+func (h *StructHelper) quote(name string) string {
+	if h.engine == opts.SQLEngineMySQL {
+		return fmt.Sprintf("`%s`", name)
+	}
+	return fmt.Sprintf("\"%s\"", name)
+}
+
+func (h *StructHelper) placeholder(position int) string {
+	if h.engine == opts.SQLEnginePostgresql {
+		return fmt.Sprintf("$%d", position)
+	}
+	return "?"
+}
+
+// placeholderFormat returns the placeholder that is numbered by fmt.Sprintf at runtime.
+func (h *StructHelper) placeholderFormat() string {
+	if h.engine == opts.SQLEnginePostgresql {
+		return "$%d"
+	}
+	return "?"
+}
+
+// formatExpr returns the Go expression of the SQL fragment with the placeholders built by placeholderFormat.
+// The numbered placeholders get the positions following the value of the offset expression.
+func (h *StructHelper) formatExpr(sql string, offset string, count int) string {
+	if h.engine != opts.SQLEnginePostgresql {
+		return strconv.Quote(sql)
+	}
+	positions := make([]string, count)
+	for i := range positions {
+		positions[i] = fmt.Sprintf("%s+%d", offset, i+1)
+	}
+	return fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(sql), strings.Join(positions, ", "))
+}
+
+func (h *StructHelper) batchSize(paramsPerRow int) int {
+	if paramsPerRow == 0 {
+		return 1
+	}
+	size := maxQueryParams[h.engine] / paramsPerRow
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+func NewStructHelper(s model.Struct, options *opts.Options) *StructHelper {
+	return &StructHelper{
+		s:                s,
+		driver:           options.Driver(),
+		engine:           options.Engine,
+		disableReturning: options.SqliteDisableReturning,
+	}
 }
@@ -0,0 +1,448 @@
+package renderer_test
+
+import (
+	"github.com/debugger84/sqlc-fixture/internal/gotype"
+	"github.com/debugger84/sqlc-fixture/internal/gotype/db"
+	"github.com/debugger84/sqlc-fixture/internal/imports"
+	"github.com/debugger84/sqlc-fixture/internal/model"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"github.com/debugger84/sqlc-fixture/internal/renderer"
+	"github.com/debugger84/sqlc-fixture/internal/sqltype"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// newTable creates the table with the columns written as "name type" with the "not null" suffix for NOT NULL columns.
+func newTable(schema, name string, columns ...string) *plugin.Table {
+	rel := &plugin.Identifier{Schema: schema, Name: name}
+	table := &plugin.Table{Rel: rel}
+	for _, c := range columns {
+		definition, notNull := strings.CutSuffix(c, " not null")
+		columnName, sqlType, _ := strings.Cut(definition, " ")
+		table.Columns = append(
+			table.Columns,
+			&plugin.Column{Name: columnName, NotNull: notNull, Table: rel, Type: &plugin.Identifier{Name: sqlType}},
+		)
+	}
+	return table
+}
+
+// newStruct builds the struct of the table with the plugin options of the engine
+// and the enums and the composite types of the schemas.
+func newStruct(
+	t *testing.T,
+	engine opts.SQLEngine,
+	pluginOptions string,
+	table *plugin.Table,
+	schemas ...*plugin.Schema,
+) (model.Struct, *opts.Options) {
+	t.Helper()
+	options, err := opts.Parse(
+		&plugin.GenerateRequest{
+			Settings:      &plugin.Settings{Engine: string(engine)},
+			PluginOptions: []byte(pluginOptions),
+		},
+	)
+	require.NoError(t, err)
+	transformer, err := db.NewDbTOGoTypeTransformer(engine, sqltype.NewCustomTypes(schemas, options), options)
+	require.NoError(t, err)
+
+	return *model.NewStruct(table, options, gotype.NewGoTypeFormatter(transformer, options)), options
+}
+
+func newMembershipStruct(t *testing.T, engine opts.SQLEngine, sqlPackage string) (model.Struct, *opts.Options) {
+	return newStruct(
+		t,
+		engine,
+		`{"package":"fixture","sql_package":"`+sqlPackage+`","default_schema":"public",`+
+			`"primary_keys_columns":["(memberships.tenant_id, memberships.user_id)"]}`,
+		newTable("public", "memberships", "tenant_id int not null", "user_id bigint not null", "role text not null"),
+	)
+}
+
+func TestStructHelper(t *testing.T) {
+	t.Run(
+		"postgresql", func(t *testing.T) {
+			h := renderer.NewStructHelper(newMembershipStruct(t, opts.SQLEnginePostgresql, opts.SQLPackagePGXV5))
+			assert.Equal(t, `"public"."memberships"`, h.TableName())
+			assert.Equal(t, `"tenant_id" = $1 AND "user_id" = $2`, h.PrimaryKeyCondition(0))
+			assert.Equal(
+				t,
+				`SELECT "tenant_id", "user_id", "role" FROM "public"."memberships" WHERE "tenant_id" = $1 AND "user_id" = $2`,
+				h.SelectSql(),
+			)
+			assert.Contains(t, h.UpdateSql(), `"role" = $1`)
+			assert.Contains(t, h.UpdateSql(), `WHERE "tenant_id" = $2 AND "user_id" = $3`)
+			assert.True(t, h.HasReturning())
+			assert.Equal(t, "QueryRow", h.QueryRowFunc())
+			assert.True(t, h.HasBatch())
+			assert.Equal(
+				t,
+				`fmt.Sprintf("(\"tenant_id\" = $%d AND \"user_id\" = $%d)", i*2+1, i*2+2)`,
+				h.CleanupConditionExpr("i*2"),
+			)
+		},
+	)
+
+	t.Run(
+		"mysql", func(t *testing.T) {
+			h := renderer.NewStructHelper(newMembershipStruct(t, opts.SQLEngineMySQL, opts.SQLPackageStandard))
+			assert.Equal(t, "`memberships`", h.TableName())
+			assert.Equal(t, "DELETE FROM `memberships` WHERE `tenant_id` = ? AND `user_id` = ?", h.DeleteSql())
+			assert.Equal(
+				t,
+				"SELECT EXISTS (SELECT 1 FROM `memberships` WHERE `tenant_id` = ? AND `user_id` = ?)",
+				h.ExistsSql(),
+			)
+			assert.Equal(t, "SELECT COUNT(*) FROM `memberships`", h.CountSql())
+			assert.Equal(
+				t,
+				"SELECT `tenant_id`, `user_id`, `role` FROM `memberships` ORDER BY `tenant_id`, `user_id`",
+				h.SelectAllSql(),
+			)
+			assert.Equal(t, "QueryContext", h.QueryFunc())
+			assert.Contains(t, h.UpdateSql(), "`role` = ?\n        WHERE `tenant_id` = ? AND `user_id` = ?")
+			assert.False(t, h.HasReturning())
+			assert.Nil(t, h.AutoIncrementField())
+			assert.Equal(t, "ExecContext", h.ExecFunc())
+			assert.True(t, h.HasMultiRowInsert())
+			assert.Equal(t, `"(?, ?, ?)"`, h.RowPlaceholdersExpr("i*3"))
+			assert.Equal(t, 21845, h.InsertBatchSize())
+			assert.Equal(t, 500, h.DeleteBatchSize())
+
+			fields := h.UpdateFields()
+			require.Len(t, fields, 3)
+			assert.Equal(t, "role", fields[0].DBName())
+			assert.Equal(t, "tenant_id", fields[1].DBName())
+			assert.Equal(t, "user_id", fields[2].DBName())
+		},
+	)
+
+	t.Run(
+		"sqlite", func(t *testing.T) {
+			s, options := newMembershipStruct(t, opts.SQLEngineSQLite, opts.SQLPackageStandard)
+			h := renderer.NewStructHelper(s, options)
+			assert.Equal(t, `"memberships"`, h.TableName())
+			assert.Equal(t, `"tenant_id" = ? AND "user_id" = ?`, h.PrimaryKeyCondition(0))
+			assert.True(t, h.HasReturning())
+			assert.Equal(t, "QueryRowContext", h.QueryRowFunc())
+			assert.False(t, h.HasMultiRowInsert())
+
+			options.SqliteDisableReturning = true
+			h = renderer.NewStructHelper(s, options)
+			assert.False(t, h.HasReturning())
+			assert.NotContains(t, h.InsertSql(), "RETURNING")
+		},
+	)
+
+	t.Run(
+		"sqlite table without schema", func(t *testing.T) {
+			// The default schema of the SQLite catalog taken by the generator without the default_schema option.
+			s, options := newStruct(
+				t,
+				opts.SQLEngineSQLite,
+				`{"package":"fixture","sql_package":"database/sql","default_schema":"main"}`,
+				newTable("", "users", "id integer not null", "name text not null"),
+			)
+			h := renderer.NewStructHelper(s, options)
+			assert.Equal(t, "User", s.Type().TypeName())
+			assert.Equal(t, `"users"`, h.TableName())
+			assert.Equal(t, `SELECT "id", "name" FROM "users" WHERE "id" = ?`, h.SelectSql())
+			assert.Equal(t, `DELETE FROM "users" WHERE "id" = ?`, h.DeleteSql())
+		},
+	)
+
+	t.Run(
+		"catalog default schema", func(t *testing.T) {
+			// Without the default_schema option the generator takes the default schema of the catalog.
+			newHelper := func(schema, name string) (model.Struct, *renderer.StructHelper) {
+				s, options := newStruct(
+					t,
+					opts.SQLEngineMySQL,
+					`{"package":"fixture","sql_package":"database/sql","default_schema":"public"}`,
+					newTable(schema, name, "id bigint not null"),
+				)
+				return s, renderer.NewStructHelper(s, options)
+			}
+
+			s, h := newHelper("", "users")
+			assert.Equal(t, "User", s.Type().TypeName())
+			assert.Equal(t, "`users`", h.TableName())
+			assert.Equal(t, "INSERT INTO `users` (`id`) VALUES", h.InsertManySql())
+
+			s, h = newHelper("public", "users")
+			assert.Equal(t, "User", s.Type().TypeName())
+			assert.Equal(t, "`users`", h.TableName())
+
+			s, h = newHelper("audit", "logs")
+			assert.Equal(t, "AuditLog", s.Type().TypeName())
+			assert.Equal(t, "`audit`.`logs`", h.TableName())
+		},
+	)
+
+	t.Run(
+		"mysql time rounding", func(t *testing.T) {
+			table := newTable("", "audit_logs", "event varchar not null", "created_at datetime not null", "seen_at timestamp")
+			table.Columns[1].Length = 23
+			table.Columns[2].Length = 19
+			s, options := newStruct(t, opts.SQLEngineMySQL, `{"package":"fixture","sql_package":"database/sql"}`, table)
+			h := renderer.NewStructHelper(s, options)
+
+			fields := h.RoundedTimeFields()
+			require.Len(t, fields, 2)
+			assert.Equal(t, "f.entity.CreatedAt = f.entity.CreatedAt.Round(time.Millisecond)", h.TimeRounding("f.entity", fields[0]))
+			assert.Equal(t, "f.entity.SeenAt.Time = f.entity.SeenAt.Time.Round(time.Second)", h.TimeRounding("f.entity", fields[1]))
+			assert.Contains(t, h.GetImports(), imports.Import{Path: "time"})
+
+			options.Engine = opts.SQLEngineSQLite
+			assert.Empty(t, renderer.NewStructHelper(s, options).RoundedTimeFields())
+		},
+	)
+
+	t.Run(
+		"lib/pq arrays", func(t *testing.T) {
+			s, options := newMembershipStruct(t, opts.SQLEnginePostgresql, opts.SQLPackageStandard)
+			h := renderer.NewStructHelper(s, options)
+			assert.NotContains(t, h.GetImports(), imports.Import{Path: "github.com/lib/pq"})
+
+			table := newTable("public", "posts", "id int8 not null", "tags text not null")
+			table.Columns[1].IsArray = true
+			table.Columns[1].ArrayDims = 1
+			post, options := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"database/sql","default_schema":"public"}`,
+				table,
+			)
+			h = renderer.NewStructHelper(post, options)
+			fields := post.Fields()
+			assert.Equal(t, "f.entity.ID", h.Arg("f.entity", fields[0]))
+			assert.Equal(t, "pq.Array(f.entity.Tags)", h.Arg("f.entity", fields[1]))
+			assert.Equal(t, "pq.Array(&f.entity.Tags)", h.ScanArg("f.entity", fields[1]))
+			assert.Equal(t, "github.com/lib/pq", h.GetImports()[0].Path)
+			assert.Equal(t, "QueryRowContext", h.QueryRowFunc())
+		},
+	)
+
+	t.Run(
+		"foreign key values", func(t *testing.T) {
+			s, _ := newMembershipStruct(t, opts.SQLEnginePostgresql, opts.SQLPackageStandard)
+			post, options := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"database/sql","default_schema":"public"}`,
+				newTable("public", "posts", "author_id bigint not null", "editor_id bigint"),
+			)
+			h := renderer.NewStructHelper(post, options)
+			userID := s.Fields()[1]
+			fields := post.Fields()
+			assert.Equal(t, "u.entity.UserID", h.KeyValue(fields[0], userID, "u.entity.UserID"))
+			assert.Equal(
+				t,
+				"sql.NullInt64{Int64: u.entity.UserID, Valid: true}",
+				h.KeyValue(fields[1], userID, "u.entity.UserID"),
+			)
+		},
+	)
+
+	t.Run(
+		"cleanup strategies", func(t *testing.T) {
+			newHelper := func(engine opts.SQLEngine, cleanup string) *renderer.StructHelper {
+				return renderer.NewStructHelper(
+					newStruct(
+						t,
+						engine,
+						`{"package":"fixture","cleanup":[`+cleanup+`]}`,
+						newTable("", "users", "id serial not null", "deleted_at timestamp"),
+					),
+				)
+			}
+
+			h := newHelper(opts.SQLEnginePostgresql, `{"table":"users","strategy":"soft_delete"}`)
+			assert.True(t, h.HasKeyCleanup())
+			assert.Equal(t, `UPDATE "users" SET "deleted_at" = CURRENT_TIMESTAMP WHERE "id" = $1`, h.DeleteSql())
+
+			h = newHelper(opts.SQLEnginePostgresql, `{"table":"users","strategy":"truncate_cascade","reset_sequences":true}`)
+			assert.False(t, h.HasKeyCleanup())
+			assert.True(t, h.HasTableCleanup())
+			assert.Equal(t, `TRUNCATE TABLE "users" RESTART IDENTITY CASCADE`, h.TableCleanupSql())
+			assert.Empty(t, h.ResetSequenceSql())
+
+			h = newHelper(opts.SQLEnginePostgresql, `{"table":"users","reset_sequences":true}`)
+			assert.Equal(
+				t,
+				`SELECT setval(pg_get_serial_sequence('"users"', 'id'), COALESCE((SELECT MAX("id") FROM "users"), 0) + 1, false)`,
+				h.ResetSequenceSql(),
+			)
+
+			h = newHelper(opts.SQLEngineSQLite, `{"table":"users","strategy":"delete_where","where":"id > 1"}`)
+			assert.Equal(t, `DELETE FROM "users" WHERE id > 1`, h.TableCleanupSql())
+
+			h = newHelper(opts.SQLEngineMySQL, `{"table":"users","strategy":"none"}`)
+			assert.False(t, h.HasKeyCleanup())
+			assert.False(t, h.HasTableCleanup())
+		},
+	)
+
+	t.Run(
+		"generated columns", func(t *testing.T) {
+			table := newTable("", "users", "id bigserial not null", "created_at timestamp not null")
+			s, options := newStruct(t, opts.SQLEnginePostgresql, `{"package":"fixture","sql_package":"database/sql"}`, table)
+			fields := s.Fields()
+			assert.True(t, fields[0].IsGenerated())
+			assert.False(t, fields[1].IsGenerated())
+			h := renderer.NewStructHelper(s, options)
+			assert.False(t, h.HasMultiRowInsert())
+			assert.False(t, h.AllFieldsGenerated())
+			assert.Equal(t, `INSERT INTO "users"`, h.InsertIntoSql())
+			assert.Equal(t, "`\"id\"`", h.ColumnLiteral(fields[0]))
+			assert.Equal(t, `fmt.Sprintf("$%d", len(args)+1)`, h.PlaceholderExpr("len(args)"))
+
+			s, options = newStruct(
+				t,
+				opts.SQLEngineMySQL,
+				`{"package":"fixture","sql_package":"database/sql","db_generated_columns":["(users.id, users.created_at)"]}`,
+				table,
+			)
+			h = renderer.NewStructHelper(s, options)
+			assert.True(t, h.AllFieldsGenerated())
+			assert.Equal(t, "INSERT INTO `users` () VALUES ()", h.DefaultValuesInsertSql())
+			assert.Equal(t, `"?"`, h.PlaceholderExpr("len(args)"))
+		},
+	)
+
+	t.Run(
+		"unique keys", func(t *testing.T) {
+			s, options := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"database/sql","default_schema":"public",`+
+					`"unique_columns":["(memberships.tenant_id, memberships.role)","orders.number"]}`,
+				newTable("public", "memberships", "tenant_id int not null", "role text not null"),
+			)
+
+			keys := s.UniqueKeys()
+			require.Len(t, keys, 1)
+			assert.Equal(t, "TenantIDRole", keys[0].Name())
+			h := renderer.NewStructHelper(s, options)
+			assert.Equal(
+				t,
+				`SELECT "tenant_id", "role" FROM "public"."memberships" WHERE "tenant_id" = $1 AND "role" = $2`,
+				h.SelectByUniqueKeySql(keys[0]),
+			)
+			assert.Equal(t, "sql.ErrNoRows", h.NoRowsErr())
+			assert.Contains(t, h.GetImports(), imports.Import{Path: "database/sql"})
+		},
+	)
+
+	t.Run(
+		"enum values", func(t *testing.T) {
+			s, _ := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				newTable("public", "users", "status user_status not null", "previous_status user_status"),
+				&plugin.Schema{
+					Name:  "public",
+					Enums: []*plugin.Enum{{Name: "user_status", Vals: []string{"active", "on-hold"}}},
+				},
+			)
+
+			fields := s.Fields()
+			require.Len(t, fields, 2)
+			assert.Equal(
+				t,
+				[]model.EnumValue{
+					{Name: "StatusActive", Value: "active", Expr: `models.UserStatus("active")`},
+					{Name: "StatusOnHold", Value: "on-hold", Expr: `models.UserStatus("on-hold")`},
+				},
+				fields[0].EnumValues(),
+			)
+			assert.Equal(t, `models.UserStatus("active")`, fields[0].DefaultValue())
+			assert.Equal(
+				t,
+				`models.NullUserStatus{UserStatus: models.UserStatus("active"), Valid: true}`,
+				fields[1].EnumValues()[0].Expr,
+			)
+			assert.Empty(t, fields[1].DefaultValue())
+		},
+	)
+	t.Run(
+		"composite types", func(t *testing.T) {
+			s, options := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models",`+
+					`"composite_types":["address(street text, zip_code int4, verified boolean)"]}`,
+				newTable("public", "users", "home address not null", "office address"),
+				&plugin.Schema{Name: "public", CompositeTypes: []*plugin.CompositeType{{Name: "address"}}},
+			)
+			h := renderer.NewStructHelper(s, options)
+
+			fields := s.Fields()
+			require.Len(t, fields, 2)
+			require.NotNil(t, fields[0].Composite())
+			assert.Equal(t, "AddressComposite", fields[0].Composite().Name())
+			assert.Equal(
+				t,
+				[]model.CompositeField{
+					{Name: "Street", DBName: "street", Type: "string"},
+					{Name: "ZipCode", DBName: "zip_code", Type: "int32"},
+					{Name: "Verified", DBName: "verified", Type: "bool"},
+				},
+				fields[0].Composite().Fields(),
+			)
+			assert.Equal(t, "AddressComposite{}.String()", fields[0].DefaultValue())
+			assert.Equal(t, "c.entity.Home = home.String()", h.CompositeAssign(fields[0], "c.entity.Home", "home"))
+			assert.Empty(t, fields[1].DefaultValue())
+			assert.Equal(
+				t,
+				"c.entity.Office = sql.NullString{String: office.String(), Valid: true}",
+				h.CompositeAssign(fields[1], "c.entity.Office", "office"),
+			)
+			assert.Equal(
+				t,
+				"err := value.UnmarshalText([]byte(f.entity.Home))\nreturn value, err",
+				h.CompositeDecode(fields[0], "f.entity.Home", "value"),
+			)
+			assert.Equal(
+				t,
+				"if !f.entity.Office.Valid {\nreturn value, nil\n}\nerr := value.UnmarshalText([]byte(f.entity.Office.String))\nreturn value, err",
+				h.CompositeDecode(fields[1], "f.entity.Office", "value"),
+			)
+		},
+	)
+	t.Run(
+		"excluded fields", func(t *testing.T) {
+			pluginOptions := `{"package":"fixture","sql_package":"database/sql","default_schema":"public",` +
+				`"model_import":"example.com/app/models","exclude":["User.userId","User.created_at"]}`
+			users := newTable(
+				"public",
+				"users",
+				"id bigint not null",
+				"user_id bigint not null",
+				"email text not null",
+				"created_at timestamptz not null",
+			)
+			s, options := newStruct(t, opts.SQLEnginePostgresql, pluginOptions, users)
+			h := renderer.NewStructHelper(s, options)
+
+			names := make([]string, 0, len(s.Fields()))
+			for _, field := range s.Fields() {
+				names = append(names, field.Name())
+			}
+			assert.Equal(t, []string{"ID", "Email"}, names)
+			assert.NotContains(t, h.InsertSql(), "user_id")
+			assert.NotContains(t, h.InsertSql(), "created_at")
+
+			code := render(t, opts.SQLEnginePostgresql, pluginOptions, users)["fixture/user.go"]
+			assert.Contains(t, code, "&c.entity.Email,")
+			assert.NotContains(t, code, "&c.entity.UserID,")
+			assert.NotContains(t, code, "&c.entity.CreatedAt,")
+			assert.NotContains(t, code, "func (f *UserFixture) UserID(")
+		},
+	)
+}
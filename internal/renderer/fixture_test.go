@@ -0,0 +1,350 @@
+package renderer_test
+
+import (
+	"github.com/debugger84/sqlc-fixture/internal/imports"
+	"github.com/debugger84/sqlc-fixture/internal/model"
+	"github.com/debugger84/sqlc-fixture/internal/opts"
+	"github.com/debugger84/sqlc-fixture/internal/renderer"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// shadowedPackageUsages returns the functions using the package by the name shadowed by a parameter of the function.
+func shadowedPackageUsages(file *ast.File, pkg string) []string {
+	usages := make([]string, 0)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		shadowed := false
+		for _, param := range fn.Type.Params.List {
+			for _, name := range param.Names {
+				if name.Name == pkg {
+					shadowed = true
+				}
+			}
+		}
+		if !shadowed {
+			continue
+		}
+		ast.Inspect(
+			fn.Body, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == pkg {
+						usages = append(usages, fn.Name.Name+": "+pkg+"."+sel.Sel.Name)
+					}
+				}
+				return true
+			},
+		)
+	}
+
+	return usages
+}
+
+// funcDecls parses the rendered file and returns its functions and methods by their names.
+func funcDecls(t *testing.T, name string, contents string) map[string]*ast.FuncDecl {
+	t.Helper()
+	parsed, err := parser.ParseFile(token.NewFileSet(), name, contents, 0)
+	require.NoError(t, err, name)
+	decls := make(map[string]*ast.FuncDecl)
+	for _, decl := range parsed.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			decls[fn.Name.Name] = fn
+		}
+	}
+	return decls
+}
+
+// calls returns the calls made by the function printed like in the source, e.g. "f.CreateCtx(testContext(tb), tb)".
+func calls(fn *ast.FuncDecl) []string {
+	printed := make([]string, 0)
+	ast.Inspect(
+		fn.Body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				printed = append(printed, types.ExprString(call))
+			}
+			return true
+		},
+	)
+	return printed
+}
+
+// render renders the fixtures of the tables with the plugin options of the engine.
+func render(t *testing.T, engine opts.SQLEngine, pluginOptions string, tables ...*plugin.Table) map[string]string {
+	t.Helper()
+	structs := make([]model.Struct, 0, len(tables))
+	var options *opts.Options
+	for _, table := range tables {
+		var s model.Struct
+		s, options = newStruct(t, engine, pluginOptions, table)
+		structs = append(structs, s)
+	}
+
+	files, err := renderer.NewFixtureRenderer(structs, options, imports.NewImportBuilder(options)).Render()
+	require.NoError(t, err)
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[file.Name] = string(file.Contents)
+	}
+
+	return contents
+}
+
+var usersTable = newTable("public", "users", "id bigint not null", "email text not null")
+
+func TestFixtureRenderer(t *testing.T) {
+	t.Run(
+		"models package named db", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/db"}`,
+				usersTable,
+			)
+			require.NotEmpty(t, files)
+			for name, contents := range files {
+				parsed, err := parser.ParseFile(token.NewFileSet(), name, contents, 0)
+				require.NoError(t, err, name)
+				assert.Empty(t, shadowedPackageUsages(parsed, "db"), name)
+			}
+		},
+	)
+
+	t.Run(
+		"support files", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				usersTable,
+			)
+			require.Contains(t, files, "fixture/zz_fixtures_test_context.go")
+			assert.Contains(t, files["fixture/zz_fixtures_test_context.go"], "func testContext(")
+			assert.NotContains(t, files["fixture/zz_fixtures.go"], "func testContext(")
+			require.Contains(t, files, "fixture/zz_fixtures_savepoint.go")
+			assert.Contains(
+				t,
+				files["fixture/zz_fixtures_savepoint.go"],
+				"func FixtureSavepoint(tb testing.TB, tx models.DBTX)",
+			)
+			assert.NotContains(t, files["fixture/zz_fixtures.go"], "func FixtureSavepoint(")
+			require.Contains(t, files, "fixture/zz_fixtures_session.go")
+			assert.Contains(t, files["fixture/zz_fixtures_session.go"], "type FixtureSession struct")
+			assert.NotContains(t, files["fixture/zz_fixtures.go"], "type FixtureSession struct")
+		},
+	)
+	t.Run(
+		"create fills the fixture", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				usersTable,
+			)
+			parsed, err := parser.ParseFile(token.NewFileSet(), "user.go", files["fixture/user.go"], 0)
+			require.NoError(t, err)
+			var createCtx *ast.FuncDecl
+			for _, decl := range parsed.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "CreateCtx" {
+					createCtx = fn
+				}
+			}
+			require.NotNil(t, createCtx)
+			assigned := false
+			ast.Inspect(
+				createCtx.Body, func(n ast.Node) bool {
+					if assign, ok := n.(*ast.AssignStmt); ok {
+						if sel, ok := assign.Lhs[0].(*ast.SelectorExpr); ok && sel.Sel.Name == "entity" {
+							assigned = sel.X.(*ast.Ident).Name == "f"
+						}
+					}
+					return true
+				},
+			)
+			assert.True(t, assigned, "CreateCtx should fill the entity of the receiver")
+		},
+	)
+	t.Run(
+		"fixtures aggregate", func(t *testing.T) {
+			pluginOptions := `{"package":"fixture","sql_package":"pgx/v5","default_schema":"public",` +
+				`"model_import":"example.com/app/models","views":["user_stats"]}`
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				pluginOptions,
+				usersTable,
+				newTable("public", "posts", "id bigint not null", "title text not null"),
+				newTable("public", "user_stats", "user_id bigint not null", "posts bigint not null"),
+			)
+			decls := funcDecls(t, "zz_fixtures.go", files["fixture/zz_fixtures.go"])
+
+			require.Contains(t, decls, "NewFixtures")
+			assert.Equal(t, "func(conn models.DBTX) *Fixtures", types.ExprString(decls["NewFixtures"].Type))
+			assert.ElementsMatch(
+				t,
+				[]string{
+					"NewUserFixture(conn, models.User{})",
+					"NewPostFixture(conn, models.Post{})",
+					"NewUserStatView(conn)",
+				},
+				calls(decls["NewFixtures"]),
+			)
+			assert.ElementsMatch(
+				t,
+				[]string{"f.User.InTx(tx)", "f.Post.InTx(tx)", "f.UserStat.InTx(tx)"},
+				calls(decls["InTx"]),
+			)
+			assert.ElementsMatch(
+				t,
+				[]string{"f.User.InSession(session)", "f.Post.InSession(session)"},
+				calls(decls["InSession"]),
+			)
+			assert.Contains(t, files["fixture/zz_fixtures.go"], "UserStat *UserStatView")
+		},
+	)
+	t.Run(
+		"test context", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				usersTable,
+			)
+			testContext := funcDecls(t, "zz_fixtures_test_context.go", files["fixture/zz_fixtures_test_context.go"])
+			require.Contains(t, testContext, "testContext")
+			assert.Equal(t, []string{"t.Context()", "context.Background()"}, calls(testContext["testContext"]))
+
+			decls := funcDecls(t, "user.go", files["fixture/user.go"])
+			for name, call := range map[string]string{
+				"Create":      "f.CreateCtx(testContext(tb), tb)",
+				"CreateMany":  "f.CreateManyCtx(testContext(tb), tb, n, vary)",
+				"PullUpdates": "f.PullUpdatesCtx(testContext(tb), tb)",
+				"PushUpdates": "f.PushUpdatesCtx(testContext(tb), tb)",
+			} {
+				require.Contains(t, decls, name)
+				assert.Contains(t, calls(decls[name]), call, name)
+			}
+			assert.Contains(t, calls(decls["CreateCtx"]), "f.insert(ctx, tb)")
+			assert.Contains(t, calls(decls["CreateManyCtx"]), "f.insertMany(ctx, tb, n, vary)")
+			assert.Contains(t, calls(decls["PullUpdatesCtx"]), "f.Reload(ctx)")
+			assert.Contains(t, calls(decls["PushUpdatesCtx"]), "f.Update(ctx)")
+			// The test context is canceled before the cleanup callbacks run.
+			assert.Contains(t, calls(decls["Cleanup"]), "f.remove(context.Background())")
+		},
+	)
+	t.Run(
+		"error-returning methods", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				usersTable,
+				newTable("public", "events", "name text not null"),
+			)
+			decls := funcDecls(t, "user.go", files["fixture/user.go"])
+			for name, signature := range map[string]string{
+				"Insert": "func(ctx context.Context) (*UserFixture, error)",
+				"InsertMany": "func(ctx context.Context, n int, vary func(i int, f *UserFixture) *UserFixture) " +
+					"([]*UserFixture, error)",
+				"Reload": "func(ctx context.Context) (*UserFixture, error)",
+				"Update": "func(ctx context.Context) (*UserFixture, error)",
+			} {
+				require.Contains(t, decls, name)
+				assert.Equal(t, signature, types.ExprString(decls[name].Type), name)
+				for _, call := range calls(decls[name]) {
+					assert.NotContains(t, call, "tb.", name)
+				}
+			}
+			// The rows are recorded in a FixtureSession instead of the cleanup of a test.
+			assert.Equal(t, []string{"f.insert(ctx, nil)", "c.track()"}, calls(decls["Insert"]))
+			assert.Equal(t, []string{"f.insertMany(ctx, nil, n, vary)", "f.trackMany(fixtures)"}, calls(decls["InsertMany"]))
+
+			decls = funcDecls(t, "event.go", files["fixture/event.go"])
+			assert.Contains(t, decls, "Insert")
+			assert.Contains(t, decls, "InsertMany")
+			assert.NotContains(t, decls, "Reload")
+			assert.NotContains(t, decls, "Update")
+		},
+	)
+	t.Run(
+		"savepoints", func(t *testing.T) {
+			for sqlPackage, exec := range map[string]string{"pgx/v5": "tx.Exec", "database/sql": "tx.ExecContext"} {
+				files := render(
+					t,
+					opts.SQLEnginePostgresql,
+					`{"package":"fixture","sql_package":"`+sqlPackage+`","default_schema":"public",`+
+						`"model_import":"example.com/app/models"}`,
+					usersTable,
+				)
+				decls := funcDecls(t, "zz_fixtures_savepoint.go", files["fixture/zz_fixtures_savepoint.go"])
+				require.Contains(t, decls, "FixtureSavepoint", sqlPackage)
+				fnCalls := calls(decls["FixtureSavepoint"])
+				assert.Contains(t, fnCalls, exec+`(testContext(tb), "SAVEPOINT " + name)`, sqlPackage)
+				assert.Contains(t, fnCalls, exec+`(context.Background(), "ROLLBACK TO SAVEPOINT " + name)`, sqlPackage)
+				assert.Contains(t, fnCalls, "savepointSequence.Add(1)", sqlPackage)
+			}
+		},
+	)
+	t.Run(
+		"transaction-bound fixtures", func(t *testing.T) {
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				usersTable,
+			)
+			decls := funcDecls(t, "user.go", files["fixture/user.go"])
+			require.Contains(t, decls, "InTx")
+			assert.Equal(t, "func(tx models.DBTX) *UserFixture", types.ExprString(decls["InTx"].Type))
+
+			// A fixture bound to a transaction returns before registering the cleanup of its rows.
+			for _, name := range []string{"Cleanup", "cleanupMany"} {
+				require.Contains(t, decls, name)
+				first, ok := decls[name].Body.List[0].(*ast.IfStmt)
+				require.True(t, ok, name)
+				assert.Contains(t, types.ExprString(first.Cond), "f.inTx", name)
+				_, returns := first.Body.List[0].(*ast.ReturnStmt)
+				assert.True(t, returns, name)
+			}
+			for _, name := range []string{"track", "trackMany"} {
+				assert.Contains(t, types.ExprString(decls[name].Body.List[0].(*ast.IfStmt).Cond), "f.inTx", name)
+			}
+		},
+	)
+	t.Run(
+		"sessions table", func(t *testing.T) {
+			sessions := newTable("public", "sessions", "id bigint not null", "token text not null")
+			files := render(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				sessions,
+				usersTable,
+			)
+			assert.Contains(t, files["fixture/session.go"], "type SessionFixture struct")
+			assert.Contains(t, files["fixture/zz_fixtures_session.go"], "type FixtureSession struct")
+			assert.Contains(t, files["fixture/zz_fixtures.go"], "Session *SessionFixture")
+		},
+	)
+
+	t.Run(
+		"duplicate files", func(t *testing.T) {
+			s, options := newStruct(
+				t,
+				opts.SQLEnginePostgresql,
+				`{"package":"fixture","sql_package":"pgx/v5","default_schema":"public","model_import":"example.com/app/models"}`,
+				newTable("public", "zz_fixtures_sessions", "id bigint not null"),
+			)
+			_, err := renderer.NewFixtureRenderer([]model.Struct{s}, options, imports.NewImportBuilder(options)).Render()
+			assert.ErrorContains(t, err, "the file fixture/zz_fixtures_session.go is generated twice")
+		},
+	)
+}
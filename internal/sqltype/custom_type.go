@@ -21,6 +21,8 @@ type CustomType struct {
 	Schema      string
 	Kind        CustomTypeKind
 	IsNullable  bool
+	// Values are the values of an enum in the order of declaration.
+	Values []string
 }
 
 func NewCustomTypes(
@@ -50,12 +52,14 @@ func NewCustomTypes(
 					Schema:      schema.Name,
 					Kind:        EnumType,
 					IsNullable:  true,
+					Values:      enum.Vals,
 				}, CustomType{
 					GoType:      *goType,
 					SqlTypeName: enum.Name,
 					Schema:      schema.Name,
 					Kind:        EnumType,
 					IsNullable:  false,
+					Values:      enum.Vals,
 				},
 			)
 		}